@@ -2,55 +2,237 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/urfave/cli/v3"
 	"github.com/vaguevoid/cloud-cli/internal/api"
 	"github.com/vaguevoid/cloud-cli/internal/domain/account"
+	"github.com/vaguevoid/cloud-cli/internal/domain/config"
+	"github.com/vaguevoid/cloud-cli/internal/domain/project"
 	"github.com/vaguevoid/cloud-cli/internal/domain/share"
 	"github.com/vaguevoid/cloud-cli/internal/lib/httpx"
+	"github.com/vaguevoid/cloud-cli/internal/lib/output"
 	"github.com/vaguevoid/cloud-cli/internal/lib/pp"
+	"github.com/vaguevoid/cloud-cli/internal/lib/progress"
+	"github.com/vaguevoid/cloud-cli/internal/lib/prompt"
 	"github.com/vaguevoid/cloud-cli/internal/lib/system"
 )
 
 //-------------------------------------------------------------------------------------------------
 
 const (
-	CommandName              = "void-cloud"
-	CommandDescription       = "access to the Void Cloud Platform"
-	CommandVersion           = "0.0.1"
-	ProductionURL            = "https://play.void.dev/"
-	LoginCommandName         = "login"
-	LoginCommandDescription  = "tell us who you are"
-	DeployCommandName        = "deploy"
-	DeployCommandDescription = "share your game with others"
+	CommandName                = "void-cloud"
+	CommandDescription         = "access to the Void Cloud Platform"
+	CommandVersion             = "0.0.1"
+	ProductionURL              = "https://play.void.dev/"
+	LoginCommandName           = "login"
+	LoginCommandDescription    = "tell us who you are"
+	LogoutCommandName          = "logout"
+	LogoutCommandDescription   = "forget who you are"
+	WhoamiCommandName          = "whoami"
+	WhoamiCommandDescription   = "show who you are logged in as"
+	ProfileCommandName         = "profile"
+	ProfileCommandDescription  = "manage named profiles for different servers, orgs and accounts"
+	ConfigCommandName          = "config"
+	ConfigCommandDescription   = "show the effective configuration and where each value came from"
+	OrgsCommandName            = "orgs"
+	OrgsCommandDescription     = "list the organizations you belong to"
+	GamesCommandName           = "games"
+	GamesCommandDescription    = "list the games in an organization"
+	DeploysCommandName         = "deploys"
+	DeploysCommandDescription  = "list, inspect, pin, protect and delete past deploys"
+	LabelsCommandName          = "labels"
+	LabelsCommandDescription   = "list, move, rename and delete deploy labels"
+	PromoteCommandName         = "promote"
+	PromoteCommandDescription  = "redeploy the files behind one label under another, without uploading them again"
+	RollbackCommandName        = "rollback"
+	RollbackCommandDescription = "serve a previous deploy under a label again"
+	InitCommandName            = "init"
+	InitCommandDescription     = "link a directory to an organization and game"
+	DeployCommandName          = "deploy"
+	DeployCommandDescription   = "share your game with others"
+	DefaultRetries             = 3
+	LoginTimeout               = 2 * time.Minute
 )
 
 //-------------------------------------------------------------------------------------------------
 
 func main() {
 
-	cmd := &cli.Command{
+	cmd := rootCommand()
+
+	ctx, stop := trapSignals()
+	defer stop()
+
+	err := cmd.Run(ctx, protectStdinArg(cmd, os.Args))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if hint := errorHint(err); hint != "" {
+			fmt.Fprintln(os.Stderr, hint)
+		}
+		os.Exit(1)
+	}
+
+}
+
+func rootCommand() *cli.Command {
+	return &cli.Command{
 		Name:    CommandName,
 		Usage:   CommandDescription,
 		Version: CommandVersion,
+		Flags:   []cli.Flag{outputFlag(), profileFlag(), configFlag()},
 		Commands: []*cli.Command{
 			loginCommand(),
+			logoutCommand(),
+			whoamiCommand(),
+			profileCommand(),
+			configCommand(),
+			orgsCommand(),
+			gamesCommand(),
+			initCommand(),
 			deployCommand(),
+			deploysCommand(),
+			labelsCommand(),
+			promoteCommand(),
+			rollbackCommand(),
 		},
 	}
+}
 
-	err := cmd.Run(context.Background(), os.Args)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+//-------------------------------------------------------------------------------------------------
+
+// urfave/cli stops parsing at a lone "-" and drops every argument after it, so
+// when it is the PATH of deploy it is swapped for a placeholder that parses as
+// an ordinary positional arg. Any other "-" (e.g. the value of --label) is left
+// alone.
+const stdinArg = "<stdin>"
+
+func protectStdinArg(root *cli.Command, args []string) []string {
+	args = slices.Clone(args)
+	cmd := root
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return args // everything after it is kept as is
+		case arg != share.StdinPath && strings.HasPrefix(arg, "-"):
+			if !strings.Contains(arg, "=") && takesValue(root, cmd, strings.TrimLeft(arg, "-")) {
+				i++ // skip the flag value
+			}
+		case cmd == root:
+			cmd = root.Command(arg)
+			if cmd == nil || cmd.Name != DeployCommandName {
+				return args
+			}
+		default:
+			if arg == share.StdinPath {
+				args[i] = stdinArg
+			}
+			return args // only the first positional arg is PATH
+		}
+	}
+	return args
+}
+
+func takesValue(root *cli.Command, cmd *cli.Command, name string) bool {
+	for _, flag := range slices.Concat(cmd.Flags, root.Flags) {
+		if slices.Contains(flag.Names(), name) {
+			f, ok := flag.(cli.DocGenerationFlag)
+			return ok && f.TakesValue()
+		}
+	}
+	return false
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func errorHint(err error) string {
+	switch {
+	case errors.Is(err, account.ErrNotLoggedIn):
+		return fmt.Sprintf("Run `%s login` first (or pass --token)", CommandName)
+	case api.IsUnauthorized(err):
+		return fmt.Sprintf("Your session has expired or is invalid, run `%s login` (or pass --token)", CommandName)
+	case api.IsForbidden(err):
+		return "You do not have access to this organization or game"
+	case api.IsNotFound(err) && api.ErrorResource(err) != "":
+		return fmt.Sprintf("Not found, check that %s exists", api.ErrorResource(err))
+	case api.IsNotFound(err):
+		return "Not found, check the --org and --game values"
+	case api.IsQuotaExceeded(err):
+		return "Your organization has exceeded its quota, delete old deploys or upgrade your plan"
+	default:
+		return ""
 	}
+}
+
+//-------------------------------------------------------------------------------------------------
 
+// trapSignals cancels the returned context on the first SIGINT/SIGTERM so
+// commands can clean up, a second signal exits immediately.
+func trapSignals() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		if _, ok := <-signals; !ok {
+			return
+		}
+		fmt.Fprintln(os.Stderr, "\nInterrupted, cleaning up (press Ctrl-C again to force exit) ...")
+		cancel()
+		if _, ok := <-signals; ok {
+			os.Exit(130)
+		}
+	}()
+	stop := func() {
+		signal.Stop(signals)
+		close(signals)
+		cancel()
+	}
+	return ctx, stop
 }
 
 //-------------------------------------------------------------------------------------------------
 
+func outputFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    "output",
+		Usage:   "output `FORMAT` (text, json or ndjson)",
+		Sources: cli.EnvVars("OUTPUT"),
+		Value:   "text",
+		Validator: func(value string) error {
+			_, err := output.ParseFormat(value)
+			return err
+		},
+	}
+}
+
+func profileFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    "profile",
+		Usage:   "use the named `PROFILE` from the config file",
+		Sources: cli.EnvVars("PROFILE"),
+	}
+}
+
+func configFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:        "config",
+		Usage:       "config `FILE`",
+		Sources:     cli.EnvVars("CONFIG"),
+		DefaultText: "~/.config/void-cloud/config.toml",
+	}
+}
+
 func serverFlag() *cli.StringFlag {
 	return &cli.StringFlag{
 		Name:    "server",
@@ -76,6 +258,105 @@ func gameFlag() *cli.StringFlag {
 	}
 }
 
+func concurrencyFlag() *cli.IntFlag {
+	return &cli.IntFlag{
+		Name:    "concurrency",
+		Usage:   "number of files to upload in parallel",
+		Sources: cli.EnvVars("CONCURRENCY"),
+		Value:   share.UploadConcurrency,
+	}
+}
+
+func retriesFlag() *cli.IntFlag {
+	return &cli.IntFlag{
+		Name:    "retries",
+		Usage:   "number of times to retry a failed request",
+		Sources: cli.EnvVars("RETRIES"),
+		Value:   DefaultRetries,
+	}
+}
+
+func ignoreFlag() *cli.StringSliceFlag {
+	return &cli.StringSliceFlag{
+		Name:  "ignore",
+		Usage: "ignore files matching `PATTERN` (in addition to .voidignore)",
+	}
+}
+
+func includeFlag() *cli.StringSliceFlag {
+	return &cli.StringSliceFlag{
+		Name:  "include",
+		Usage: "include files matching `PATTERN` even if ignored",
+	}
+}
+
+func timeoutFlag(value time.Duration) *cli.DurationFlag {
+	return &cli.DurationFlag{
+		Name:    "timeout",
+		Usage:   "give up after `DURATION` (e.g. 90s, 10m)",
+		Sources: cli.EnvVars("TIMEOUT"),
+		Value:   value,
+	}
+}
+
+func resumeFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:  "resume",
+		Usage: "resume an interrupted deploy",
+	}
+}
+
+func hashConcurrencyFlag() *cli.IntFlag {
+	return &cli.IntFlag{
+		Name:        "hash-concurrency",
+		Usage:       "number of files to hash in parallel",
+		Sources:     cli.EnvVars("HASH_CONCURRENCY"),
+		DefaultText: "number of CPUs",
+	}
+}
+
+func keepPendingFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:  "keep-pending",
+		Usage: "when interrupted, keep the pending deploy on the server so it can be resumed",
+	}
+}
+
+func noCacheFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:  "no-cache",
+		Usage: "re-hash every file instead of using the local hash cache",
+	}
+}
+
+func dryRunFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "show what would be uploaded, then cancel the deploy",
+	}
+}
+
+func manifestOnlyFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:  "manifest-only",
+		Usage: "print the local manifest without contacting the server",
+	}
+}
+
+func deviceFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:  "device",
+		Usage: "login by entering a code on another device, used automatically over SSH or without a display",
+	}
+}
+
+func revokeFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:  "revoke",
+		Usage: "also revoke the token on the server",
+	}
+}
+
 func tokenFlag() *cli.StringFlag {
 	return &cli.StringFlag{
 		Name:    "token",
@@ -84,6 +365,84 @@ func tokenFlag() *cli.StringFlag {
 	}
 }
 
+func createGameFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:  "create-game",
+		Usage: "create a new game called `NAME` instead of picking an existing one",
+	}
+}
+
+func pathFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:        "path",
+		Usage:       "build `DIR` to deploy, relative to the project directory",
+		DefaultText: "detected",
+	}
+}
+
+func labelFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:  "label",
+		Usage: "default deploy `LABEL`",
+	}
+}
+
+func forceFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:  "force",
+		Usage: "overwrite an existing project config",
+	}
+}
+
+func noInputFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:    "no-input",
+		Usage:   "never prompt, fail if a required value is missing (the default when stdin is not a terminal)",
+		Sources: cli.EnvVars("NO_INPUT"),
+	}
+}
+
+func yesFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:    "yes",
+		Aliases: []string{"y"},
+		Usage:   "don't ask for confirmation (required when stdin is not a terminal)",
+	}
+}
+
+func pinFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:  "pin",
+		Usage: "pin the deploy so it is never cleaned up automatically",
+	}
+}
+
+func passwordFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "password",
+			Usage:   "require `PASSWORD` to view the deploy (visible to other processes, prefer --password-stdin)",
+			Sources: cli.EnvVars("DEPLOY_PASSWORD"),
+		},
+		&cli.BoolFlag{
+			Name:  "password-stdin",
+			Usage: "read the password from stdin",
+		},
+		&cli.BoolFlag{
+			Name:  "password-prompt",
+			Usage: "prompt for the password without echoing it",
+		},
+	}
+}
+
+func toFlag() *cli.IntFlag {
+	return &cli.IntFlag{
+		Name:        "to",
+		Usage:       "roll back to `DEPLOY_ID`",
+		DefaultText: "the deploy before the current one",
+	}
+}
+
 //-------------------------------------------------------------------------------------------------
 
 func loginCommand() *cli.Command {
@@ -91,21 +450,89 @@ func loginCommand() *cli.Command {
 	return &cli.Command{
 		Name:               LoginCommandName,
 		Usage:              LoginCommandDescription,
-		Flags:              []cli.Flag{serverFlag()},
+		Flags:              []cli.Flag{serverFlag(), timeoutFlag(LoginTimeout), deviceFlag()},
+		CustomHelpTemplate: SubcommandHelpTemplate,
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			printer, err := buildPrinter(cmd)
+			if err != nil {
+				return err
+			}
+			settings, err := loadSettings(cmd)
+			if err != nil {
+				return err
+			}
+			user, err := login(ctx, cmd, printer, settings)
+			if err != nil {
+				return err
+			}
+			printer.Result("login", user, func(w io.Writer) {
+				fmt.Fprintln(w, "You are logged in")
+				fmt.Fprintln(w, pp.JSON(user))
+			})
+			return nil
+		},
+	}
+}
+
+// login runs the browser (or device code) login flow, it is shared with
+// commands that login on demand such as init.
+func login(ctx context.Context, cmd *cli.Command, printer *output.Printer, settings *settings) (*account.User, error) {
+	runtime := system.DefaultRuntime()
+	device := cmd.Bool("device") || !runtime.HasDisplay()
+	printer.Printf("logging in to %s ...\n", settings.Server)
+	return account.Login(ctx, &account.LoginCommand{
+		Server:  settings.Server,
+		Runtime: runtime,
+		Keyring: settings.Keyring(),
+		Timeout: cmd.Duration("timeout"),
+		Device:  device,
+		OnDeviceCode: func(code *account.DeviceCode) {
+			printer.Printf("To login, visit %s and enter the code %s\n", code.VerificationURL, code.UserCode)
+			if code.VerificationURLComplete != "" {
+				printer.Printf("or open %s\n", code.VerificationURLComplete)
+			}
+			printer.Printf("waiting for approval ...\n")
+			printer.Event("device_code", code)
+		},
+	})
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func logoutCommand() *cli.Command {
+
+	return &cli.Command{
+		Name:               LogoutCommandName,
+		Usage:              LogoutCommandDescription,
+		Flags:              []cli.Flag{serverFlag(), revokeFlag(), timeoutFlag(0)},
 		CustomHelpTemplate: SubcommandHelpTemplate,
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			server := cmd.String("server")
-			fmt.Println("logging in to", server, "...")
-			user, err := account.Login(&account.LoginCommand{
+			ctx, cancel := withTimeout(ctx, cmd)
+			defer cancel()
+			printer, err := buildPrinter(cmd)
+			if err != nil {
+				return err
+			}
+			settings, err := loadSettings(cmd)
+			if err != nil {
+				return err
+			}
+			server := settings.Server
+			ok, err := account.Logout(ctx, &account.LogoutCommand{
 				Server:  server,
-				Runtime: system.DefaultRuntime(),
-				Keyring: system.DefaultKeyring(server),
+				Keyring: settings.Keyring(),
+				Revoke:  cmd.Bool("revoke"),
 			})
 			if err != nil {
 				return err
 			}
-			fmt.Println("You are logged in")
-			fmt.Println(pp.JSON(user))
+			printer.Result("logout", map[string]any{"server": server, "loggedOut": ok}, func(w io.Writer) {
+				if ok {
+					fmt.Fprintln(w, "You are logged out of", server)
+				} else {
+					fmt.Fprintln(w, "You were not logged in to", server)
+				}
+			})
 			return nil
 		},
 	}
@@ -113,74 +540,1612 @@ func loginCommand() *cli.Command {
 
 //-------------------------------------------------------------------------------------------------
 
-func deployCommand() *cli.Command {
+func whoamiCommand() *cli.Command {
 
 	return &cli.Command{
-		Name:      DeployCommandName,
-		Usage:     DeployCommandDescription,
-		ArgsUsage: "PATH [LABEL]",
+		Name:               WhoamiCommandName,
+		Usage:              WhoamiCommandDescription,
+		Flags:              []cli.Flag{serverFlag(), tokenFlag(), timeoutFlag(0)},
+		CustomHelpTemplate: SubcommandHelpTemplate,
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			ctx, cancel := withTimeout(ctx, cmd)
+			defer cancel()
+			printer, err := buildPrinter(cmd)
+			if err != nil {
+				return err
+			}
+			settings, err := loadSettings(cmd)
+			if err != nil {
+				return err
+			}
+			server := settings.Server
+			session, err := account.Whoami(ctx, &account.WhoamiCommand{
+				Server:  server,
+				Keyring: settings.Keyring(),
+				Token:   settings.Token,
+			})
+			if err != nil {
+				return err
+			}
+			printer.Result("whoami", session, func(w io.Writer) {
+				fmt.Fprintf(w, "Logged in to %s as %s (%d)\n", server, session.User.Name, session.User.ID)
+				for _, org := range session.User.Organizations {
+					fmt.Fprintf(w, "  organization %s (%d)\n", org.Name, org.ID)
+				}
+				if session.ExpiresAt != nil {
+					fmt.Fprintf(w, "Token expires %s\n", session.ExpiresAt.Local().Format(time.RFC1123))
+				}
+			})
+			return nil
+		},
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+
+type profileSummary struct {
+	Name    string `json:"name"`
+	Current bool   `json:"current"`
+	config.Profile
+}
+
+func profileCommand() *cli.Command {
+
+	return &cli.Command{
+		Name:               ProfileCommandName,
+		Usage:              ProfileCommandDescription,
+		CustomHelpTemplate: SubcommandHelpTemplate,
+		Commands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "list profiles",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					printer, err := buildPrinter(cmd)
+					if err != nil {
+						return err
+					}
+					cfg, err := loadConfig(cmd)
+					if err != nil {
+						return err
+					}
+					current := cfg.Active(cmd.String("profile"))
+					profiles := make([]*profileSummary, 0)
+					for _, name := range cfg.Names() {
+						profile, _ := cfg.Profile(name)
+						profiles = append(profiles, &profileSummary{Name: name, Current: name == current, Profile: maskToken(*profile)})
+					}
+					printer.Result("profiles", profiles, func(w io.Writer) {
+						for _, profile := range profiles {
+							marker := " "
+							if profile.Current {
+								marker = "*"
+							}
+							fmt.Fprintf(w, "%s %-16s %s\n", marker, profile.Name, profile.Server)
+						}
+					})
+					return nil
+				},
+			},
+			{
+				Name:      "use",
+				Usage:     "make PROFILE the current profile",
+				ArgsUsage: "PROFILE",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					printer, err := buildPrinter(cmd)
+					if err != nil {
+						return err
+					}
+					name := cmd.Args().Get(0)
+					if name == "" {
+						return fmt.Errorf("missing required argument: PROFILE")
+					}
+					cfg, err := loadConfig(cmd)
+					if err != nil {
+						return err
+					}
+					err = cfg.Use(name)
+					if err != nil {
+						return err
+					}
+					printer.Result("profile", map[string]string{"current": name}, func(w io.Writer) {
+						fmt.Fprintf(w, "Using profile %s\n", name)
+					})
+					return nil
+				},
+			},
+			{
+				Name:      "show",
+				Usage:     "show the settings of PROFILE (default: the current profile)",
+				ArgsUsage: "[PROFILE]",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					printer, err := buildPrinter(cmd)
+					if err != nil {
+						return err
+					}
+					cfg, err := loadConfig(cmd)
+					if err != nil {
+						return err
+					}
+					current := cfg.Active(cmd.String("profile"))
+					name := cmd.Args().Get(0)
+					if name == "" {
+						name = current
+					}
+					profile, err := cfg.Profile(name)
+					if err != nil {
+						return err
+					}
+					summary := &profileSummary{Name: name, Current: name == current, Profile: maskToken(*profile)}
+					printer.Result("profile", summary, func(w io.Writer) {
+						fmt.Fprintf(w, "profile: %s\n", summary.Name)
+						fmt.Fprintf(w, "config:  %s\n", cfg.Path())
+						fmt.Fprintf(w, "server:  %s\n", summary.Server)
+						fmt.Fprintf(w, "org:     %s\n", summary.Org)
+						fmt.Fprintf(w, "game:    %s\n", summary.Game)
+						fmt.Fprintf(w, "token:   %s\n", summary.Token)
+					})
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func maskToken(profile config.Profile) config.Profile {
+	if profile.Token != "" {
+		profile.Token = "********"
+	}
+	return profile
+}
+
+//-------------------------------------------------------------------------------------------------
+
+type configValue struct {
+	Key    string `json:"key"`
+	Value  any    `json:"value"`
+	Source string `json:"source,omitempty"`
+}
+
+func configCommand() *cli.Command {
+
+	return &cli.Command{
+		Name:      ConfigCommandName,
+		Usage:     ConfigCommandDescription,
+		ArgsUsage: "[PATH [LABEL]]",
 		Flags: []cli.Flag{
 			serverFlag(),
 			orgFlag(),
 			gameFlag(),
 			tokenFlag(),
+			concurrencyFlag(),
+			ignoreFlag(),
 		},
 		CustomHelpTemplate: SubcommandHelpTemplate,
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			org := cmd.String("org")
-			game := cmd.String("game")
-			path := cmd.Args().Get(0)
-			label := cmd.Args().Get(1)
-
-			if path == "" {
-				return fmt.Errorf("missing required argument: PATH")
+			printer, err := buildPrinter(cmd)
+			if err != nil {
+				return err
 			}
-
-			api, err := buildAPIClient(cmd)
+			settings, err := loadSettings(cmd)
 			if err != nil {
 				return err
 			}
-
-			fmt.Printf("Deploying %s ...\n", path)
-			result, err := share.Deploy(&share.DeployCommand{
-				API:   api,
-				Org:   org,
-				Game:  game,
-				Label: label,
-				Path:  path,
-				OnStarted: func(deployID int64, manifest []share.DeployEntry, incremental []share.DeployEntry) {
-					total := len(manifest)
-					count := len(incremental)
-					if total == count {
-						fmt.Printf("deploying ALL %d files\n", total)
+			token := settings.Token
+			if token != "" {
+				token = "********"
+			}
+			values := []*configValue{
+				{Key: "profile", Value: settings.Profile},
+				{Key: "server", Value: settings.Server},
+				{Key: "org", Value: settings.Org},
+				{Key: "game", Value: settings.Game},
+				{Key: "token", Value: token},
+				{Key: "path", Value: settings.Path},
+				{Key: "label", Value: settings.Label},
+				{Key: "ignore", Value: settings.Ignore},
+				{Key: "concurrency", Value: settings.Concurrency},
+			}
+			for _, value := range values {
+				value.Source = settings.Sources[value.Key]
+			}
+			printer.Result("config", values, func(w io.Writer) {
+				for _, value := range values {
+					text := fmt.Sprint(value.Value)
+					if ignore, ok := value.Value.([]string); ok {
+						text = strings.Join(ignore, ", ")
+					}
+					if value.Source == "" {
+						fmt.Fprintf(w, "%-12s %s\n", value.Key+":", text)
 					} else {
-						fmt.Printf("deploying %d / %d files\n", count, total)
+						fmt.Fprintf(w, "%-12s %s (%s)\n", value.Key+":", text, value.Source)
 					}
-				},
-				OnUpload: func(deployID int64, path string) {
-					fmt.Printf("deploying %s\n", path)
-				},
+				}
 			})
-			if err != nil {
-				return err
-			}
-
-			fmt.Printf("Deployed to %s\n", result.URL)
 			return nil
 		},
 	}
 }
 
-// -------------------------------------------------------------------------------------------------
+//-------------------------------------------------------------------------------------------------
 
-func buildAPIClient(cmd *cli.Command) (*api.Client, error) {
-	server := cmd.String("server")
-	token := cmd.String("token")
-	if token == "" {
-		jwt, _ := system.DefaultKeyring(server).Get(httpx.ParamJWT)
-		token = jwt
+func orgsCommand() *cli.Command {
+
+	return &cli.Command{
+		Name:               OrgsCommandName,
+		Usage:              OrgsCommandDescription,
+		CustomHelpTemplate: SubcommandHelpTemplate,
+		Commands: []*cli.Command{
+			{
+				Name:               "list",
+				Usage:              "list the organizations you belong to",
+				CustomHelpTemplate: SubcommandHelpTemplate,
+				Flags:              []cli.Flag{serverFlag(), tokenFlag(), retriesFlag(), timeoutFlag(0)},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					ctx, cancel := withTimeout(ctx, cmd)
+					defer cancel()
+					printer, err := buildPrinter(cmd)
+					if err != nil {
+						return err
+					}
+					settings, err := loadSettings(cmd)
+					if err != nil {
+						return err
+					}
+					client, err := buildAPIClient(cmd, settings)
+					if err != nil {
+						return err
+					}
+					orgs, err := client.ListOrganizations(ctx)
+					if err != nil {
+						return err
+					}
+					if orgs == nil {
+						orgs = []api.Organization{}
+					}
+					printer.Result("orgs", orgs, func(w io.Writer) {
+						if len(orgs) == 0 {
+							fmt.Fprintln(w, "You are not a member of any organization")
+							return
+						}
+						rows := make([][]string, len(orgs))
+						for i, org := range orgs {
+							rows[i] = []string{org.Slug, org.Name, fmt.Sprint(org.ID)}
+						}
+						output.Table(w, []string{"org", "name", "id"}, rows)
+					})
+					return nil
+				},
+			},
+		},
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func gamesCommand() *cli.Command {
+
+	return &cli.Command{
+		Name:               GamesCommandName,
+		Usage:              GamesCommandDescription,
+		CustomHelpTemplate: SubcommandHelpTemplate,
+		Commands: []*cli.Command{
+			{
+				Name:               "list",
+				Usage:              "list the games in an organization",
+				CustomHelpTemplate: SubcommandHelpTemplate,
+				Flags:              []cli.Flag{serverFlag(), orgFlag(), tokenFlag(), retriesFlag(), timeoutFlag(0)},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					ctx, cancel := withTimeout(ctx, cmd)
+					defer cancel()
+					printer, err := buildPrinter(cmd)
+					if err != nil {
+						return err
+					}
+					settings, err := loadSettings(cmd)
+					if err != nil {
+						return err
+					}
+					if settings.Org == "" {
+						return fmt.Errorf("missing --org (run `%s %s list` to see your organizations)", CommandName, OrgsCommandName)
+					}
+					client, err := buildAPIClient(cmd, settings)
+					if err != nil {
+						return err
+					}
+					games, err := client.ListGames(ctx, settings.Org)
+					if err != nil {
+						return err
+					}
+					if games == nil {
+						games = []api.Game{}
+					}
+					printer.Result("games", games, func(w io.Writer) {
+						if len(games) == 0 {
+							fmt.Fprintf(w, "There are no games in %s\n", settings.Org)
+							return
+						}
+						rows := make([][]string, len(games))
+						for i, game := range games {
+							rows[i] = []string{game.Slug, game.Name, fmt.Sprint(game.ID)}
+						}
+						output.Table(w, []string{"game", "name", "id"}, rows)
+					})
+					return nil
+				},
+			},
+		},
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func initCommand() *cli.Command {
+
+	return &cli.Command{
+		Name:      InitCommandName,
+		Usage:     InitCommandDescription,
+		ArgsUsage: "[DIR]",
+		Flags: []cli.Flag{
+			serverFlag(),
+			orgFlag(),
+			gameFlag(),
+			createGameFlag(),
+			pathFlag(),
+			labelFlag(),
+			tokenFlag(),
+			forceFlag(),
+			noInputFlag(),
+			retriesFlag(),
+			timeoutFlag(LoginTimeout),
+			deviceFlag(),
+		},
+		CustomHelpTemplate: SubcommandHelpTemplate,
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			printer, err := buildPrinter(cmd)
+			if err != nil {
+				return err
+			}
+			settings, err := loadSettings(cmd)
+			if err != nil {
+				return err
+			}
+			dir := cmd.Args().First()
+			if dir == "" {
+				dir = "."
+			}
+			dir, err = filepath.Abs(dir)
+			if err != nil {
+				return err
+			}
+
+			var prompter project.Prompter
+			if !cmd.Bool("no-input") && system.IsTerminal(os.Stdin) {
+				prompter = prompt.New(os.Stdin, printer.Chatter())
+			}
+
+			if settings.Token == "" && !settings.Keyring().Has(httpx.ParamJWT) {
+				if prompter == nil {
+					return account.ErrNotLoggedIn
+				}
+				if _, err := login(ctx, cmd, printer, settings); err != nil {
+					return err
+				}
+			}
+
+			client, err := buildAPIClient(cmd, settings)
+			if err != nil {
+				return err
+			}
+
+			result, err := project.Init(ctx, &project.InitCommand{
+				API:        client,
+				Dir:        dir,
+				Org:        cmd.String("org"),
+				Game:       cmd.String("game"),
+				CreateGame: cmd.String("create-game"),
+				Path:       cmd.String("path"),
+				Label:      cmd.String("label"),
+				Force:      cmd.Bool("force"),
+				Prompt:     prompter,
+				OnCreated: func(game *api.Game) {
+					printer.Printf("Created game %s (%s)\n", game.Name, game.Slug)
+					printer.Event("created", game)
+				},
+			})
+			if err != nil {
+				return err
+			}
+
+			printer.Result("initialized", result, func(w io.Writer) {
+				fmt.Fprintf(w, "Linked %s to %s/%s\n", dir, result.Org, result.Game)
+				fmt.Fprintf(w, "Wrote %s\n", result.File)
+				if result.Path == "" {
+					fmt.Fprintf(w, "No build directory found, set path in %s or pass PATH to `%s %s`\n", config.ProjectFile, CommandName, DeployCommandName)
+				} else {
+					fmt.Fprintf(w, "Run `%s %s` to share your game\n", CommandName, DeployCommandName)
+				}
+			})
+			return nil
+		},
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+
+type deploySummary struct {
+	DeployID      int64   `json:"deployID"`
+	Slug          string  `json:"slug,omitempty"`
+	URL           string  `json:"url"`
+	Pinned        bool    `json:"pinned"`
+	Protected     bool    `json:"protected"`
+	Files         int     `json:"files"`
+	Bytes         int64   `json:"bytes"`
+	UploadedFiles int     `json:"uploadedFiles"`
+	UploadedBytes int64   `json:"uploadedBytes"`
+	Duration      float64 `json:"duration"`
+}
+
+type deployPlan struct {
+	DeployID    int64               `json:"deployID,omitempty"`
+	Org         string              `json:"org,omitempty"`
+	Game        string              `json:"game,omitempty"`
+	Label       string              `json:"label,omitempty"`
+	Files       int                 `json:"files"`
+	Bytes       int64               `json:"bytes"`
+	Manifest    []share.DeployEntry `json:"manifest"`
+	Incremental []share.DeployEntry `json:"incremental,omitempty"`
+}
+
+type deployInterrupted struct {
+	DeployID int64  `json:"deployID"`
+	Uploaded int    `json:"uploaded"`
+	Pending  int    `json:"pending"`
+	Aborted  bool   `json:"aborted"`
+	AbortErr string `json:"abortError,omitempty"`
+}
+
+func deployCommand() *cli.Command {
+
+	return &cli.Command{
+		Name:      DeployCommandName,
+		Usage:     DeployCommandDescription,
+		ArgsUsage: "[PATH [LABEL]]",
+		Flags: append([]cli.Flag{
+			serverFlag(),
+			orgFlag(),
+			gameFlag(),
+			tokenFlag(),
+			concurrencyFlag(),
+			retriesFlag(),
+			timeoutFlag(0),
+			ignoreFlag(),
+			includeFlag(),
+			resumeFlag(),
+			keepPendingFlag(),
+			noCacheFlag(),
+			hashConcurrencyFlag(),
+			dryRunFlag(),
+			manifestOnlyFlag(),
+			pinFlag(),
+		}, passwordFlags()...),
+		CustomHelpTemplate: SubcommandHelpTemplate,
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			printer, err := buildPrinter(cmd)
+			if err != nil {
+				return err
+			}
+			settings, err := loadSettings(cmd)
+			if err != nil {
+				return err
+			}
+			if settings.Path == stdinArg {
+				settings.Path = share.StdinPath
+			}
+			if settings.Path == share.StdinPath && cmd.Bool("password-stdin") {
+				return fmt.Errorf("cannot read both the archive and the password from stdin")
+			} else if settings.Path == share.StdinPath && cmd.Bool("keep-pending") {
+				return fmt.Errorf("cannot use --keep-pending with stdin, a deploy from stdin cannot be resumed")
+			}
+			password, err := readPassword(cmd, printer, false)
+			if err != nil {
+				return err
+			}
+			resume := cmd.Bool("resume")
+			dryRun := share.DryRunOff
+			if cmd.Bool("manifest-only") {
+				dryRun = share.DryRunLocal
+			} else if cmd.Bool("dry-run") {
+				dryRun = share.DryRunPlan
+			}
+			cacheDir := share.DefaultCacheDir()
+			if cmd.Bool("no-cache") {
+				cacheDir = ""
+			}
+			path := settings.Path
+			if path == "" {
+				return fmt.Errorf("missing required argument: PATH (or set path in %s)", config.ProjectFile)
+			}
+
+			var client *api.Client // --manifest-only never talks to the server, so it needs no token
+			if dryRun != share.DryRunLocal {
+				client, err = buildAPIClient(cmd, settings)
+				if err != nil {
+					return err
+				}
+			}
+
+			source := path
+			if path == share.StdinPath {
+				source = "archive from stdin"
+			}
+			if dryRun != share.DryRunOff {
+				printer.Printf("Planning deploy of %s ...\n", source)
+			} else if resume {
+				printer.Printf("Resuming deploy of %s ...\n", source)
+			} else {
+				printer.Printf("Deploying %s ...\n", source)
+			}
+			ctx, cancel := withTimeout(ctx, cmd)
+			defer cancel()
+
+			start := time.Now()
+			uploads := &deployProgress{out: printer.Chatter()}
+			result, err := share.Deploy(ctx, &share.DeployCommand{
+				API:             client,
+				Org:             settings.Org,
+				Game:            settings.Game,
+				Label:           settings.Label,
+				Path:            path,
+				Stdin:           os.Stdin,
+				Pin:             cmd.Bool("pin"),
+				Password:        password,
+				HashConcurrency: int(cmd.Int("hash-concurrency")),
+				Concurrency:     settings.Concurrency,
+				Ignore:          settings.Ignore,
+				Include:         cmd.StringSlice("include"),
+				Resume:          resume,
+				JournalDir:      share.DefaultJournalDir(),
+				CacheDir:        cacheDir,
+				DryRun:          dryRun,
+				AbortOnCancel:   !cmd.Bool("keep-pending"),
+				OnStarted: func(deployID int64, manifest []share.DeployEntry, incremental []share.DeployEntry) {
+					total := len(manifest)
+					count := len(incremental)
+					if total == count {
+						printer.Printf("deploying ALL %d files\n", total)
+					} else {
+						printer.Printf("deploying %d / %d files\n", count, total)
+					}
+					printer.Event("started", map[string]any{
+						"deployID":    deployID,
+						"files":       total,
+						"bytes":       share.TotalContentLength(manifest),
+						"uploadFiles": count,
+						"uploadBytes": share.TotalContentLength(incremental),
+					})
+					uploads.start(incremental)
+				},
+				OnUpload: func(deployID int64, path string) {
+					uploads.tracker.Start(path, uploads.sizes[path])
+					printer.Event("upload", map[string]any{
+						"deployID": deployID,
+						"path":     path,
+					})
+				},
+				OnProgress: func(deployID int64, path string, sent int64) {
+					uploads.tracker.Add(path, sent)
+				},
+				OnUploaded: func(deployID int64, path string) {
+					uploads.tracker.Done(path)
+					printer.Event("uploaded", map[string]any{
+						"deployID": deployID,
+						"path":     path,
+					})
+				},
+				OnFailed: func(deployID int64, path string, err error) {
+					uploads.tracker.Fail(path)
+					printer.Event("failed", map[string]any{
+						"deployID": deployID,
+						"path":     path,
+						"error":    err.Error(),
+					})
+				},
+				OnWarning: func(message string) {
+					printer.Printf("warning: %s\n", message)
+					printer.Event("warning", map[string]any{"message": message})
+				},
+			})
+			uploads.stop()
+			var interrupted *share.InterruptedError
+			if errors.As(err, &interrupted) {
+				printInterrupted(printer, interrupted)
+				return err
+			} else if err != nil {
+				return err
+			}
+
+			if dryRun != share.DryRunOff {
+				printDeployPlan(printer, settings, dryRun, result)
+				return nil
+			}
+
+			printer.Result("deployed", &deploySummary{
+				DeployID:      result.DeployID,
+				Slug:          result.Slug,
+				URL:           result.URL,
+				Pinned:        result.Pinned,
+				Protected:     result.Protected,
+				Files:         len(result.Manifest),
+				Bytes:         share.TotalContentLength(result.Manifest),
+				UploadedFiles: len(result.Incremental),
+				UploadedBytes: share.TotalContentLength(result.Incremental),
+				Duration:      time.Since(start).Seconds(),
+			}, func(w io.Writer) {
+				fmt.Fprintf(w, "Deployed to %s\n", result.URL)
+				if result.Pinned {
+					fmt.Fprintf(w, "Deploy %d is pinned and will not be cleaned up automatically\n", result.DeployID)
+				}
+				if result.Protected {
+					fmt.Fprintf(w, "Deploy %d is password protected\n", result.DeployID)
+				}
+			})
+			return nil
+		},
+	}
+}
+
+// deployProgress renders upload progress, the tracker is only created once
+// the server has told us which files need uploading.
+type deployProgress struct {
+	out      io.Writer
+	sizes    map[string]int64
+	tracker  *progress.Tracker
+	renderer *progress.Renderer
+}
+
+func (p *deployProgress) start(incremental []share.DeployEntry) {
+	p.sizes = make(map[string]int64, len(incremental))
+	for _, entry := range incremental {
+		p.sizes[entry.Path] = int64(entry.ContentLength)
+	}
+	p.tracker = progress.NewTracker(len(incremental), share.TotalContentLength(incremental))
+	p.renderer = progress.NewRenderer(p.out, p.tracker)
+	p.renderer.Start()
+}
+
+func (p *deployProgress) stop() {
+	if p.renderer != nil {
+		p.renderer.Stop()
+	}
+}
+
+func printInterrupted(printer *output.Printer, err *share.InterruptedError) {
+	event := &deployInterrupted{
+		DeployID: err.DeployID,
+		Uploaded: len(err.Uploaded),
+		Pending:  len(err.Pending),
+		Aborted:  err.Aborted,
+	}
+	if err.AbortErr != nil {
+		event.AbortErr = err.AbortErr.Error()
+	}
+	printer.Event("interrupted", event)
+
+	fmt.Fprintf(printer.Err, "Deploy %d was interrupted after uploading %d / %d files\n", err.DeployID, len(err.Uploaded), len(err.Uploaded)+len(err.Pending))
+	if err.Aborted {
+		fmt.Fprintf(printer.Err, "Pending deploy %d was cancelled on the server\n", err.DeployID)
+	} else if err.AbortErr != nil {
+		fmt.Fprintf(printer.Err, "Failed to cancel pending deploy %d: %s\n", err.DeployID, err.AbortErr)
+	} else {
+		fmt.Fprintf(printer.Err, "Pending deploy %d was kept, re-run with --resume to finish it\n", err.DeployID)
+	}
+}
+
+func printDeployPlan(printer *output.Printer, settings *settings, dryRun share.DryRunMode, result *share.DeployResult) {
+	plan := &deployPlan{
+		DeployID: result.DeployID,
+		Org:      settings.Org,
+		Game:     settings.Game,
+		Label:    settings.Label,
+		Files:    len(result.Manifest),
+		Bytes:    share.TotalContentLength(result.Manifest),
+		Manifest: result.Manifest,
+	}
+	if dryRun == share.DryRunPlan {
+		plan.Incremental = result.Incremental
+	}
+	printer.Result("plan", plan, func(w io.Writer) {
+		fmt.Fprintln(w, "Manifest:")
+		for _, entry := range result.Manifest {
+			fmt.Fprintf(w, "  %s  %10d  %s\n", entry.Blake3, entry.ContentLength, entry.Path)
+		}
+		fmt.Fprintf(w, "%d files, %d bytes\n", plan.Files, plan.Bytes)
+		if dryRun == share.DryRunPlan {
+			fmt.Fprintln(w, "Would upload:")
+			for _, entry := range result.Incremental {
+				fmt.Fprintf(w, "  %10d  %s\n", entry.ContentLength, entry.Path)
+			}
+			fmt.Fprintf(w, "%d / %d files, %d bytes\n", len(result.Incremental), len(result.Manifest), share.TotalContentLength(result.Incremental))
+			fmt.Fprintf(w, "Cancelled pending deploy %d\n", result.DeployID)
+		}
+		if plan.Org != "" && plan.Game != "" {
+			if plan.Label != "" {
+				fmt.Fprintf(w, "Target: label %s on %s/%s\n", plan.Label, plan.Org, plan.Game)
+			} else {
+				fmt.Fprintf(w, "Target: %s/%s\n", plan.Org, plan.Game)
+			}
+		}
+	})
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func deploysCommand() *cli.Command {
+
+	flags := func() []cli.Flag {
+		return []cli.Flag{serverFlag(), orgFlag(), gameFlag(), tokenFlag(), retriesFlag(), timeoutFlag(0)}
+	}
+
+	return &cli.Command{
+		Name:               DeploysCommandName,
+		Usage:              DeploysCommandDescription,
+		CustomHelpTemplate: SubcommandHelpTemplate,
+		Commands: []*cli.Command{
+			{
+				Name:               "list",
+				Usage:              "list the deploys of a game, most recent first",
+				Flags:              flags(),
+				CustomHelpTemplate: SubcommandHelpTemplate,
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					ctx, cancel := withTimeout(ctx, cmd)
+					defer cancel()
+					printer, settings, client, err := buildGameCommand(cmd)
+					if err != nil {
+						return err
+					}
+					deploys, err := client.ListDeploys(ctx, settings.Org, settings.Game)
+					if err != nil {
+						return err
+					}
+					if deploys == nil {
+						deploys = []api.Deploy{}
+					}
+					printer.Result("deploys", deploys, func(w io.Writer) {
+						if len(deploys) == 0 {
+							fmt.Fprintf(w, "There are no deploys of %s/%s\n", settings.Org, settings.Game)
+							return
+						}
+						rows := make([][]string, len(deploys))
+						for i, deploy := range deploys {
+							pinned := ""
+							if deploy.Pinned {
+								pinned = "yes"
+							}
+							rows[i] = []string{
+								fmt.Sprint(deploy.ID),
+								deploy.Label,
+								deploy.Slug,
+								deploy.CreatedAt.Local().Format(time.DateTime),
+								deploy.Author,
+								progress.FormatBytes(deploy.Size),
+								pinned,
+								deploy.URL,
+							}
+						}
+						output.Table(w, []string{"id", "label", "slug", "created", "author", "size", "pinned", "url"}, rows)
+					})
+					return nil
+				},
+			},
+			{
+				Name:               "show",
+				Usage:              "show a deploy and its manifest",
+				ArgsUsage:          "DEPLOY_ID",
+				Flags:              flags(),
+				CustomHelpTemplate: SubcommandHelpTemplate,
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					ctx, cancel := withTimeout(ctx, cmd)
+					defer cancel()
+					deployID, err := deployIDArg(cmd)
+					if err != nil {
+						return err
+					}
+					printer, settings, client, err := buildGameCommand(cmd)
+					if err != nil {
+						return err
+					}
+					deploy, err := client.GetDeploy(ctx, settings.Org, settings.Game, deployID)
+					if err != nil {
+						return err
+					}
+					printer.Result("deploy", deploy, func(w io.Writer) {
+						printDeploy(w, &deploy.Deploy)
+						if len(deploy.Manifest) == 0 {
+							return
+						}
+						fmt.Fprintln(w)
+						rows := make([][]string, len(deploy.Manifest))
+						for i, entry := range deploy.Manifest {
+							rows[i] = []string{entry.Path, progress.FormatBytes(entry.ContentLength), entry.Blake3}
+						}
+						output.Table(w, []string{"path", "size", "blake3"}, rows)
+					})
+					return nil
+				},
+			},
+			{
+				Name:               "pin",
+				Usage:              "pin a deploy so it is never cleaned up automatically",
+				ArgsUsage:          "DEPLOY_ID|LABEL",
+				Flags:              flags(),
+				CustomHelpTemplate: SubcommandHelpTemplate,
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					ctx, cancel := withTimeout(ctx, cmd)
+					defer cancel()
+					return setPinned(ctx, cmd, true)
+				},
+			},
+			{
+				Name:               "unpin",
+				Usage:              "unpin a deploy so it can be cleaned up automatically",
+				ArgsUsage:          "DEPLOY_ID|LABEL",
+				Flags:              flags(),
+				CustomHelpTemplate: SubcommandHelpTemplate,
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					ctx, cancel := withTimeout(ctx, cmd)
+					defer cancel()
+					return setPinned(ctx, cmd, false)
+				},
+			},
+			{
+				Name:               "set-password",
+				Usage:              "require a password to view a deploy",
+				ArgsUsage:          "DEPLOY_ID|LABEL",
+				Flags:              append(flags(), passwordFlags()...),
+				CustomHelpTemplate: SubcommandHelpTemplate,
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					ctx, cancel := withTimeout(ctx, cmd)
+					defer cancel()
+					return setPassword(ctx, cmd, true)
+				},
+			},
+			{
+				Name:               "clear-password",
+				Usage:              "make a password protected deploy public again",
+				ArgsUsage:          "DEPLOY_ID|LABEL",
+				Flags:              flags(),
+				CustomHelpTemplate: SubcommandHelpTemplate,
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					ctx, cancel := withTimeout(ctx, cmd)
+					defer cancel()
+					return setPassword(ctx, cmd, false)
+				},
+			},
+			{
+				Name:               "delete",
+				Usage:              "delete a deploy",
+				ArgsUsage:          "DEPLOY_ID",
+				Flags:              append(flags(), yesFlag()),
+				CustomHelpTemplate: SubcommandHelpTemplate,
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					ctx, cancel := withTimeout(ctx, cmd)
+					defer cancel()
+					deployID, err := deployIDArg(cmd)
+					if err != nil {
+						return err
+					}
+					printer, settings, client, err := buildGameCommand(cmd)
+					if err != nil {
+						return err
+					}
+					err = confirm(cmd, printer, fmt.Sprintf("Delete deploy %d of %s/%s?", deployID, settings.Org, settings.Game))
+					if err != nil {
+						return err
+					}
+					err = client.DeleteDeploy(ctx, settings.Org, settings.Game, deployID)
+					if err != nil {
+						return err
+					}
+					printer.Result("deleted", map[string]int64{"deployID": deployID}, func(w io.Writer) {
+						fmt.Fprintf(w, "Deleted deploy %d\n", deployID)
+					})
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func setPinned(ctx context.Context, cmd *cli.Command, pinned bool) error {
+	ref := cmd.Args().First()
+	if ref == "" {
+		return fmt.Errorf("missing required argument: DEPLOY_ID or LABEL")
+	}
+	printer, settings, client, err := buildGameCommand(cmd)
+	if err != nil {
+		return err
+	}
+	resolved, err := share.ResolveDeploy(ctx, client, settings.Org, settings.Game, ref)
+	if err != nil {
+		return err
+	}
+	var deploy *api.Deploy
+	if pinned {
+		deploy, err = client.PinDeploy(ctx, settings.Org, settings.Game, resolved.ID)
+	} else {
+		deploy, err = client.UnpinDeploy(ctx, settings.Org, settings.Game, resolved.ID)
+	}
+	if err != nil {
+		return err
+	}
+	printer.Result("deploy", deploy, func(w io.Writer) {
+		if deploy.Pinned {
+			fmt.Fprintf(w, "Pinned deploy %d\n", deploy.ID)
+		} else {
+			fmt.Fprintf(w, "Unpinned deploy %d\n", deploy.ID)
+		}
+	})
+	return nil
+}
+
+func setPassword(ctx context.Context, cmd *cli.Command, protect bool) error {
+	ref := cmd.Args().First()
+	if ref == "" {
+		return fmt.Errorf("missing required argument: DEPLOY_ID or LABEL")
+	}
+	printer, settings, client, err := buildGameCommand(cmd)
+	if err != nil {
+		return err
+	}
+	password := ""
+	if protect {
+		password, err = readPassword(cmd, printer, true)
+		if err != nil {
+			return err
+		}
+	}
+	deploy, err := share.ResolveDeploy(ctx, client, settings.Org, settings.Game, ref)
+	if err != nil {
+		return err
+	}
+	if protect {
+		err = client.SetDeployPassword(ctx, settings.Org, settings.Game, deploy.ID, password)
+	} else {
+		err = client.ClearDeployPassword(ctx, settings.Org, settings.Game, deploy.ID)
+	}
+	if err != nil {
+		return err
+	}
+	printer.Result("deploy", map[string]any{"deployID": deploy.ID, "protected": protect}, func(w io.Writer) {
+		if protect {
+			fmt.Fprintf(w, "Deploy %d now requires a password\n", deploy.ID)
+		} else {
+			fmt.Fprintf(w, "Deploy %d no longer requires a password\n", deploy.ID)
+		}
+	})
+	return nil
+}
+
+// readPassword returns the password given by one of the password flags, when
+// none is given it prompts if required (and there is a terminal to prompt on)
+// or returns an empty password.
+func readPassword(cmd *cli.Command, printer *output.Printer, required bool) (string, error) {
+	given := 0
+	for _, flag := range []string{"password", "password-stdin", "password-prompt"} {
+		if cmd.IsSet(flag) {
+			given++
+		}
+	}
+	if given > 1 {
+		return "", fmt.Errorf("use only one of --password, --password-stdin and --password-prompt")
+	}
+
+	var password string
+	switch {
+	case cmd.IsSet("password"):
+		password = cmd.String("password")
+	case cmd.Bool("password-stdin"):
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password from stdin: %w", err)
+		}
+		password = strings.TrimRight(string(data), "\r\n")
+	case cmd.Bool("password-prompt") || required:
+		if !system.IsTerminal(os.Stdin) {
+			return "", fmt.Errorf("%s needs --password or --password-stdin when stdin is not a terminal", cmd.FullName())
+		}
+		p := prompt.New(os.Stdin, printer.Chatter())
+		var err error
+		if password, err = p.Password("Password"); err != nil {
+			return "", err
+		}
+		again, err := p.Password("Confirm password")
+		if err != nil {
+			return "", err
+		} else if again != password {
+			return "", fmt.Errorf("passwords do not match")
+		}
+	default:
+		return "", nil
+	}
+	if password == "" {
+		return "", fmt.Errorf("password cannot be empty")
+	}
+	return password, nil
+}
+
+func printDeploy(w io.Writer, deploy *api.Deploy) {
+	fmt.Fprintf(w, "%-12s %d\n", "id:", deploy.ID)
+	if deploy.Label != "" {
+		fmt.Fprintf(w, "%-12s %s\n", "label:", deploy.Label)
+	}
+	fmt.Fprintf(w, "%-12s %s\n", "slug:", deploy.Slug)
+	fmt.Fprintf(w, "%-12s %s\n", "url:", deploy.URL)
+	fmt.Fprintf(w, "%-12s %s\n", "created:", deploy.CreatedAt.Local().Format(time.DateTime))
+	if deploy.Author != "" {
+		fmt.Fprintf(w, "%-12s %s\n", "author:", deploy.Author)
+	}
+	fmt.Fprintf(w, "%-12s %d (%s)\n", "files:", deploy.Files, progress.FormatBytes(deploy.Size))
+	fmt.Fprintf(w, "%-12s %t\n", "active:", deploy.Active)
+	fmt.Fprintf(w, "%-12s %t\n", "pinned:", deploy.Pinned)
+	fmt.Fprintf(w, "%-12s %t\n", "protected:", deploy.Protected)
+}
+
+func deployIDArg(cmd *cli.Command) (int64, error) {
+	arg := cmd.Args().First()
+	if arg == "" {
+		return 0, fmt.Errorf("missing required argument: DEPLOY_ID")
+	}
+	deployID, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || deployID <= 0 {
+		return 0, fmt.Errorf("invalid deploy ID %q", arg)
+	}
+	return deployID, nil
+}
+
+var errCancelled = errors.New("cancelled")
+
+// confirm asks before a destructive action, --yes skips the question and is
+// required when there is no terminal to ask on.
+func confirm(cmd *cli.Command, printer *output.Printer, question string) error {
+	if cmd.Bool("yes") {
+		return nil
+	} else if !system.IsTerminal(os.Stdin) {
+		return fmt.Errorf("%s needs --yes when stdin is not a terminal", cmd.FullName())
+	}
+	ok, err := prompt.New(os.Stdin, printer.Chatter()).Confirm(question, false)
+	if err != nil {
+		return err
+	} else if !ok {
+		return errCancelled
+	}
+	return nil
+}
+
+//-------------------------------------------------------------------------------------------------
+
+type labelSummary struct {
+	Name      string    `json:"name"`
+	DeployID  int64     `json:"deployID"`
+	Slug      string    `json:"slug,omitempty"`
+	URL       string    `json:"url"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func summarizeLabel(label *api.Label) *labelSummary {
+	return &labelSummary{
+		Name:      label.Name,
+		DeployID:  label.DeployID,
+		Slug:      label.Slug,
+		URL:       label.URL,
+		UpdatedAt: label.UpdatedAt,
+	}
+}
+
+func labelsCommand() *cli.Command {
+
+	flags := func() []cli.Flag {
+		return []cli.Flag{serverFlag(), orgFlag(), gameFlag(), tokenFlag(), retriesFlag(), timeoutFlag(0)}
+	}
+
+	return &cli.Command{
+		Name:               LabelsCommandName,
+		Usage:              LabelsCommandDescription,
+		CustomHelpTemplate: SubcommandHelpTemplate,
+		Commands: []*cli.Command{
+			{
+				Name:               "list",
+				Usage:              "list the labels of a game and the deploy served under each",
+				Flags:              flags(),
+				CustomHelpTemplate: SubcommandHelpTemplate,
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					ctx, cancel := withTimeout(ctx, cmd)
+					defer cancel()
+					printer, settings, client, err := buildGameCommand(cmd)
+					if err != nil {
+						return err
+					}
+					labels, err := client.ListLabels(ctx, settings.Org, settings.Game)
+					if err != nil {
+						return err
+					}
+					summaries := make([]*labelSummary, len(labels))
+					for i, label := range labels {
+						summaries[i] = summarizeLabel(&label)
+					}
+					printer.Result("labels", summaries, func(w io.Writer) {
+						if len(labels) == 0 {
+							fmt.Fprintf(w, "There are no labels for %s/%s\n", settings.Org, settings.Game)
+							return
+						}
+						rows := make([][]string, len(labels))
+						for i, label := range labels {
+							rows[i] = []string{
+								label.Name,
+								fmt.Sprint(label.DeployID),
+								label.UpdatedAt.Local().Format(time.DateTime),
+								label.URL,
+							}
+						}
+						output.Table(w, []string{"label", "deploy", "updated", "url"}, rows)
+					})
+					return nil
+				},
+			},
+			{
+				Name:               "set",
+				Usage:              "serve an existing deploy under a label, without uploading it again",
+				ArgsUsage:          "LABEL DEPLOY_ID|LABEL",
+				Flags:              flags(),
+				CustomHelpTemplate: SubcommandHelpTemplate,
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					ctx, cancel := withTimeout(ctx, cmd)
+					defer cancel()
+					name, ref := cmd.Args().Get(0), cmd.Args().Get(1)
+					if name == "" || ref == "" {
+						return fmt.Errorf("missing required arguments: LABEL and DEPLOY_ID or LABEL")
+					}
+					printer, settings, client, err := buildGameCommand(cmd)
+					if err != nil {
+						return err
+					}
+					deploy, err := share.ResolveDeploy(ctx, client, settings.Org, settings.Game, ref)
+					if err != nil {
+						return err
+					}
+					label, err := client.SetLabel(ctx, settings.Org, settings.Game, name, deploy.ID)
+					if err != nil {
+						return err
+					}
+					printer.Result("label", summarizeLabel(label), func(w io.Writer) {
+						fmt.Fprintf(w, "Label %s now serves deploy %d at %s\n", label.Name, label.DeployID, label.URL)
+					})
+					return nil
+				},
+			},
+			{
+				Name:               "rename",
+				Usage:              "rename a label, the deploy served under it is unchanged",
+				ArgsUsage:          "LABEL NEW_LABEL",
+				Flags:              flags(),
+				CustomHelpTemplate: SubcommandHelpTemplate,
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					ctx, cancel := withTimeout(ctx, cmd)
+					defer cancel()
+					name, newName := cmd.Args().Get(0), cmd.Args().Get(1)
+					if name == "" || newName == "" {
+						return fmt.Errorf("missing required arguments: LABEL and NEW_LABEL")
+					}
+					printer, settings, client, err := buildGameCommand(cmd)
+					if err != nil {
+						return err
+					}
+					label, err := client.RenameLabel(ctx, settings.Org, settings.Game, name, newName)
+					if err != nil {
+						return err
+					}
+					printer.Result("label", summarizeLabel(label), func(w io.Writer) {
+						fmt.Fprintf(w, "Renamed label %s to %s\n", name, label.Name)
+					})
+					return nil
+				},
+			},
+			{
+				Name:               "delete",
+				Usage:              "delete a label, the deploy served under it is kept",
+				ArgsUsage:          "LABEL",
+				Flags:              append(flags(), yesFlag()),
+				CustomHelpTemplate: SubcommandHelpTemplate,
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					ctx, cancel := withTimeout(ctx, cmd)
+					defer cancel()
+					name := cmd.Args().First()
+					if name == "" {
+						return fmt.Errorf("missing required argument: LABEL")
+					}
+					printer, settings, client, err := buildGameCommand(cmd)
+					if err != nil {
+						return err
+					}
+					err = confirm(cmd, printer, fmt.Sprintf("Delete label %s of %s/%s?", name, settings.Org, settings.Game))
+					if err != nil {
+						return err
+					}
+					err = client.DeleteLabel(ctx, settings.Org, settings.Game, name)
+					if err != nil {
+						return err
+					}
+					printer.Result("deleted", map[string]string{"label": name}, func(w io.Writer) {
+						fmt.Fprintf(w, "Deleted label %s\n", name)
+					})
+					return nil
+				},
+			},
+		},
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+
+type promoteSummary struct {
+	From     int64  `json:"from"`
+	DeployID int64  `json:"deployID"`
+	Label    string `json:"label"`
+	Slug     string `json:"slug,omitempty"`
+	URL      string `json:"url"`
+	Files    int    `json:"files"`
+	Bytes    int64  `json:"bytes"`
+}
+
+func promoteCommand() *cli.Command {
+
+	return &cli.Command{
+		Name:               PromoteCommandName,
+		Usage:              PromoteCommandDescription,
+		ArgsUsage:          "FROM_LABEL|DEPLOY_ID TO_LABEL",
+		Flags:              []cli.Flag{serverFlag(), orgFlag(), gameFlag(), tokenFlag(), retriesFlag(), timeoutFlag(0)},
+		CustomHelpTemplate: SubcommandHelpTemplate,
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			ctx, cancel := withTimeout(ctx, cmd)
+			defer cancel()
+			from, to := cmd.Args().Get(0), cmd.Args().Get(1)
+			if from == "" || to == "" {
+				return fmt.Errorf("missing required arguments: FROM_LABEL and TO_LABEL")
+			}
+			printer, settings, client, err := buildGameCommand(cmd)
+			if err != nil {
+				return err
+			}
+			printer.Printf("Promoting %s to %s ...\n", from, to)
+			result, err := share.Promote(ctx, &share.PromoteCommand{
+				API:  client,
+				Org:  settings.Org,
+				Game: settings.Game,
+				From: from,
+				To:   to,
+			})
+			if err != nil {
+				return err
+			}
+			printer.Result("promoted", &promoteSummary{
+				From:     result.From.ID,
+				DeployID: result.DeployID,
+				Label:    to,
+				Slug:     result.Slug,
+				URL:      result.URL,
+				Files:    len(result.Manifest),
+				Bytes:    share.TotalContentLength(result.Manifest),
+			}, func(w io.Writer) {
+				fmt.Fprintf(w, "Promoted deploy %d to %s as deploy %d (%d files, %s)\n", result.From.ID, to, result.DeployID,
+					len(result.Manifest), progress.FormatBytes(share.TotalContentLength(result.Manifest)))
+				fmt.Fprintf(w, "Deployed to %s\n", result.URL)
+			})
+			return nil
+		},
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+
+type rollbackSummary struct {
+	From     int64              `json:"from,omitempty"`
+	DeployID int64              `json:"deployID"`
+	Label    string             `json:"label"`
+	Slug     string             `json:"slug,omitempty"`
+	URL      string             `json:"url"`
+	Diff     share.ManifestDiff `json:"diff"`
+}
+
+func rollbackCommand() *cli.Command {
+
+	return &cli.Command{
+		Name:      RollbackCommandName,
+		Usage:     RollbackCommandDescription,
+		ArgsUsage: "[LABEL]",
+		Flags: []cli.Flag{
+			serverFlag(),
+			orgFlag(),
+			gameFlag(),
+			tokenFlag(),
+			retriesFlag(),
+			timeoutFlag(0),
+			toFlag(),
+			yesFlag(),
+		},
+		CustomHelpTemplate: SubcommandHelpTemplate,
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			ctx, cancel := withTimeout(ctx, cmd)
+			defer cancel()
+			printer, settings, client, err := buildGameCommand(cmd)
+			if err != nil {
+				return err
+			}
+			label := cmd.Args().First()
+			if label == "" {
+				label = settings.Label
+			}
+			if label == "" {
+				return fmt.Errorf("missing required argument: LABEL (or set label in %s)", config.ProjectFile)
+			}
+			to := cmd.Int("to")
+			if cmd.IsSet("to") && to <= 0 {
+				return fmt.Errorf("invalid deploy ID %d", to)
+			}
+
+			result, err := share.Rollback(ctx, &share.RollbackCommand{
+				API:   client,
+				Org:   settings.Org,
+				Game:  settings.Game,
+				Label: label,
+				To:    int64(to),
+				Confirm: func(plan *share.RollbackPlan) error {
+					printRollbackPlan(printer.Chatter(), plan)
+					return confirm(cmd, printer, fmt.Sprintf("Serve deploy %d as %s?", plan.To.ID, plan.Label))
+				},
+			})
+			if err != nil {
+				return err
+			}
+			summary := &rollbackSummary{
+				DeployID: result.Activation.DeployID,
+				Label:    label,
+				Slug:     result.Activation.Slug,
+				URL:      result.Activation.URL,
+				Diff:     result.Plan.Diff,
+			}
+			if result.Plan.From != nil {
+				summary.From = result.Plan.From.ID
+			}
+			printer.Result("rollback", summary, func(w io.Writer) {
+				fmt.Fprintf(w, "%s now serves deploy %d\n", label, result.Activation.DeployID)
+				fmt.Fprintln(w, result.Activation.URL)
+			})
+			return nil
+		},
+	}
+}
+
+func printRollbackPlan(w io.Writer, plan *share.RollbackPlan) {
+	describe := func(deploy *api.DeployDetail) string {
+		text := fmt.Sprintf("deploy %d (%s", deploy.ID, deploy.CreatedAt.Local().Format(time.DateTime))
+		if deploy.Author != "" {
+			text += " by " + deploy.Author
+		}
+		return text + ")"
+	}
+	if plan.From != nil {
+		fmt.Fprintf(w, "Rolling back %s from %s to %s\n", plan.Label, describe(plan.From), describe(plan.To))
+	} else {
+		fmt.Fprintf(w, "Serving %s as %s\n", describe(plan.To), plan.Label)
+	}
+	if plan.Diff.Empty() {
+		fmt.Fprintf(w, "The manifests are identical (%d files)\n", plan.Diff.Unchanged)
+		return
+	}
+	for _, entry := range plan.Diff.Added {
+		fmt.Fprintf(w, "  + %s (%s)\n", entry.Path, progress.FormatBytes(entry.ContentLength))
+	}
+	for _, entry := range plan.Diff.Removed {
+		fmt.Fprintf(w, "  - %s (%s)\n", entry.Path, progress.FormatBytes(entry.ContentLength))
+	}
+	for _, entry := range plan.Diff.Changed {
+		fmt.Fprintf(w, "  ~ %s (%s)\n", entry.Path, progress.FormatBytes(entry.ContentLength))
+	}
+	fmt.Fprintf(w, "%d added, %d removed, %d changed, %d unchanged\n", len(plan.Diff.Added), len(plan.Diff.Removed), len(plan.Diff.Changed), plan.Diff.Unchanged)
+}
+
+// -------------------------------------------------------------------------------------------------
+
+// settings are resolved from (highest first) flags and their environment
+// variables, the project's void.toml, the active profile and finally the flag
+// defaults. Sources records where each value came from.
+type settings struct {
+	Profile     string
+	Project     *config.Project
+	Server      string
+	Org         string
+	Game        string
+	Token       string
+	Path        string
+	Label       string
+	Ignore      []string
+	Concurrency int
+	Sources     map[string]string
+}
+
+type candidate struct {
+	value  string
+	source string
+}
+
+func loadSettings(cmd *cli.Command) (*settings, error) {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return nil, err
+	}
+	s := &settings{Sources: make(map[string]string)}
+
+	s.Profile = cfg.Active(cmd.String("profile"))
+	if cmd.IsSet("profile") {
+		s.Sources["profile"] = flagSource(cmd, "profile")
+	} else if cfg.Current != "" {
+		s.Sources["profile"] = "config " + cfg.Path()
+	} else {
+		s.Sources["profile"] = "default"
+	}
+	profile, err := cfg.Profile(s.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	s.Project, err = config.FindProject(cwd)
+	if err != nil {
+		return nil, err
+	}
+	project := s.Project
+	projectSource := ""
+	if project == nil {
+		project = &config.Project{}
+	} else {
+		projectSource = "project " + project.File()
+	}
+	profileSource := "profile " + s.Profile
+
+	s.Server = s.resolve(cmd, "server", candidate{profile.Server, profileSource})
+	s.Org = s.resolve(cmd, "org", candidate{project.Org, projectSource}, candidate{profile.Org, profileSource})
+	s.Game = s.resolve(cmd, "game", candidate{project.Game, projectSource}, candidate{profile.Game, profileSource})
+	s.Token = s.resolve(cmd, "token", candidate{profile.Token, profileSource})
+	s.Path = s.resolveArg(cmd, 0, "path", candidate{project.BuildPath(), projectSource})
+	s.Label = s.resolveArg(cmd, 1, "label", candidate{project.Label, projectSource})
+
+	s.Ignore = append(slices.Clone(project.Ignore), cmd.StringSlice("ignore")...) // patterns add up rather than override
+	if len(project.Ignore) > 0 && cmd.IsSet("ignore") {
+		s.Sources["ignore"] = projectSource + " + flag"
+	} else if len(project.Ignore) > 0 {
+		s.Sources["ignore"] = projectSource
+	} else if cmd.IsSet("ignore") {
+		s.Sources["ignore"] = "flag"
+	}
+
+	s.Concurrency = int(cmd.Int("concurrency"))
+	if cmd.IsSet("concurrency") {
+		s.Sources["concurrency"] = flagSource(cmd, "concurrency")
+	} else if project.Concurrency > 0 {
+		s.Concurrency = project.Concurrency
+		s.Sources["concurrency"] = projectSource
+	} else {
+		s.Sources["concurrency"] = "default"
+	}
+
+	return s, nil
+}
+
+func (s *settings) resolve(cmd *cli.Command, flag string, candidates ...candidate) string {
+	if cmd.IsSet(flag) {
+		s.Sources[flag] = flagSource(cmd, flag)
+		return cmd.String(flag)
+	}
+	for _, c := range candidates {
+		if c.value != "" {
+			s.Sources[flag] = c.source
+			return c.value
+		}
+	}
+	if value := cmd.String(flag); value != "" {
+		s.Sources[flag] = "default"
+		return value
+	}
+	return ""
+}
+
+func (s *settings) resolveArg(cmd *cli.Command, index int, name string, candidates ...candidate) string {
+	if value := cmd.Args().Get(index); value != "" {
+		s.Sources[name] = "argument"
+		return value
+	}
+	return s.resolve(cmd, name, candidates...)
+}
+
+// flagSource tells a flag given on the command line apart from one set by its
+// environment variable.
+func flagSource(cmd *cli.Command, flag string) string {
+	env := strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+	if value, ok := os.LookupEnv(env); ok && value == fmt.Sprint(cmd.Value(flag)) {
+		return "env " + env
+	}
+	return "flag --" + flag
+}
+
+func (s *settings) Keyring() *system.SystemKeyring {
+	return system.DefaultKeyring(config.KeyringName(s.Profile, s.Server))
+}
+
+func loadConfig(cmd *cli.Command) (*config.Config, error) {
+	path := cmd.String("config")
+	if path == "" {
+		path = config.DefaultPath()
+	}
+	return config.Load(path)
+}
+
+func buildAPIClient(cmd *cli.Command, settings *settings) (*api.Client, error) {
+	token := settings.Token
+	if token == "" {
+		jwt, _ := settings.Keyring().Get(httpx.ParamJWT)
+		token = jwt
+	}
+	client, err := api.NewClient(settings.Server, token)
+	if err != nil {
+		return nil, err
+	}
+	client.Retry = api.DefaultRetryPolicy(int(cmd.Int("retries")))
+	return client, nil
+}
+
+// buildGameCommand does the common setup for commands that act on a game,
+// which need an org and game from flags, void.toml or the profile.
+func buildGameCommand(cmd *cli.Command) (*output.Printer, *settings, *api.Client, error) {
+	printer, err := buildPrinter(cmd)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	settings, err := loadSettings(cmd)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if settings.Org == "" {
+		return nil, nil, nil, fmt.Errorf("missing --org (or set org in %s)", config.ProjectFile)
+	} else if settings.Game == "" {
+		return nil, nil, nil, fmt.Errorf("missing --game (or set game in %s)", config.ProjectFile)
+	}
+	client, err := buildAPIClient(cmd, settings)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return printer, settings, client, nil
+}
+
+// withTimeout gives up on the rest of a command after --timeout, when set.
+func withTimeout(ctx context.Context, cmd *cli.Command) (context.Context, context.CancelFunc) {
+	if timeout := cmd.Duration("timeout"); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+func buildPrinter(cmd *cli.Command) (*output.Printer, error) {
+	format, err := output.ParseFormat(cmd.String("output"))
+	if err != nil {
+		return nil, err
 	}
-	return api.NewClient(server, token)
+	printer := output.New(format)
+	printer.Out = cmd.Root().Writer
+	printer.Err = cmd.Root().ErrWriter
+	return printer, nil
 }
 
 //-------------------------------------------------------------------------------------------------
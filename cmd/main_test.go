@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vaguevoid/cloud-cli/internal/api"
+	"github.com/vaguevoid/cloud-cli/internal/domain/share"
+	"github.com/vaguevoid/cloud-cli/internal/lib/httpx"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+	"github.com/vaguevoid/cloud-cli/internal/test/mock"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+func run(t *testing.T, args ...string) (string, string, error) {
+	t.Setenv("CONFIG", filepath.Join(t.TempDir(), "config.toml"))
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var stdout, stderr bytes.Buffer
+	cmd := rootCommand()
+	cmd.Writer = &stdout
+	cmd.ErrWriter = &stderr
+	err := cmd.Run(t.Context(), protectStdinArg(cmd, append([]string{CommandName}, args...)))
+	return stdout.String(), stderr.String(), err
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestDeployInterruptedPrintsSummary(t *testing.T) {
+	mockDir := mock.TempDir(t)
+	mockDir.AddTextFile(t, "index.html", "<h1>Hello</h1>")
+
+	cancelled := false
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/void/snakes/deploy":
+			manifest := assert.RequestJSON[[]share.DeployEntry](t, r)
+			w.Header().Add(httpx.HeaderXDeployID, "42")
+			httpx.RespondAccepted(manifest, w)
+		case strings.HasPrefix(r.URL.Path, "/api/void/snakes/deploy/42/upload/"):
+			io.Copy(io.Discard, r.Body)
+			<-r.Context().Done() // never finishes, the deploy times out
+		case r.URL.Path == "/api/void/snakes/deploy/42/cancel":
+			cancelled = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			httpx.RespondBadRequest(fmt.Sprintf("unexpected %s", r.URL.Path), w)
+		}
+	}))
+	defer mockServer.Close()
+
+	_, stderr, err := run(t, "deploy",
+		"--server", mockServer.URL,
+		"--token", "token",
+		"--org", "void",
+		"--game", "snakes",
+		"--retries", "0",
+		"--timeout", "100ms",
+		mockDir.Dir,
+	)
+
+	var interrupted *share.InterruptedError
+	assert.True(t, errors.As(err, &interrupted))
+	assert.True(t, cancelled)
+	assert.Equal(t, "Deploy 42 was interrupted after uploading 0 / 1 files\nPending deploy 42 was cancelled on the server\n", stderr)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestErrorHintNamesMissingResource(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	_, _, err := run(t, "deploys", "show", "--server", mockServer.URL, "--token", "token", "--org", "void", "--game", "snakes", "43")
+	assert.True(t, api.IsNotFound(err))
+	assert.Equal(t, "Not found, check that deploy 43 exists", errorHint(err))
+
+	_, _, err = run(t, "deploys", "list", "--server", mockServer.URL, "--token", "token", "--org", "void", "--game", "snakes")
+	assert.True(t, api.IsNotFound(err))
+	assert.Equal(t, "Not found, check the --org and --game values", errorHint(err))
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestProtectStdinArg(t *testing.T) {
+	root := rootCommand()
+	tests := []struct {
+		args     string
+		expected string
+	}{
+		{"deploy -", "deploy <stdin>"},
+		{"deploy - qa --pin", "deploy <stdin> qa --pin"},
+		{"--output json deploy --pin --org void -", "--output json deploy --pin --org void <stdin>"},
+		{"deploy --game - dist", "deploy --game - dist"},
+		{"deploy --org - --game=- -", "deploy --org - --game=- <stdin>"},
+		{"deploy dist -", "deploy dist -"},
+		{"deploy -- -", "deploy -- -"},
+		{"labels set --org - qa 42", "labels set --org - qa 42"},
+		{"rollback --org - qa", "rollback --org - qa"},
+		{"labels delete -", "labels delete -"},
+	}
+	for _, test := range tests {
+		args := append([]string{CommandName}, strings.Fields(test.args)...)
+		expected := append([]string{CommandName}, strings.Fields(test.expected)...)
+		assert.Equal(t, expected, protectStdinArg(root, args), test.args)
+	}
+}
+
+func TestDeployWithStdinFlagValues(t *testing.T) {
+	mockDir := mock.TempDir(t)
+	mockDir.AddTextFile(t, "index.html", "<h1>Hello</h1>")
+
+	stdout, _, err := run(t, "--output", "json", "deploy", "--manifest-only", "--org", "-", "--game", "-", mockDir.Dir, "qa")
+	assert.NoError(t, err)
+
+	var plan deployPlan
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &plan))
+	assert.Equal(t, "-", plan.Org)
+	assert.Equal(t, "-", plan.Game)
+	assert.Equal(t, "qa", plan.Label)
+	assert.Equal(t, 1, plan.Files)
+}
+
+func TestManifestOnlyNeedsNoServer(t *testing.T) {
+	mockDir := mock.TempDir(t)
+	mockDir.AddTextFile(t, "index.html", "<h1>Hello</h1>")
+
+	_, _, err := run(t, "deploy", "--manifest-only", "--server", "://not-a-server", mockDir.Dir)
+	assert.NoError(t, err)
+
+	_, _, err = run(t, "deploy", "--manifest-only", "--resume", mockDir.Dir)
+	assert.Error(t, "cannot resume a dry run", err)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestLabelsPrintDeployID(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httpx.RespondOk([]api.Label{{Name: "latest", DeployID: 42, URL: "https://snakes.void.dev"}}, w)
+	}))
+	defer mockServer.Close()
+
+	stdout, _, err := run(t, "--output", "json", "labels", "list", "--server", mockServer.URL, "--token", "token", "--org", "void", "--game", "snakes")
+	assert.NoError(t, err)
+
+	var labels []map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &labels))
+	assert.Length(t, 1, labels)
+	assert.Equal[any](t, "latest", labels[0]["name"])
+	assert.Equal[any](t, float64(42), labels[0]["deployID"])
+	assert.Nil(t, labels[0]["deployId"])
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestRollbackPrintsDeployID(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch fmt.Sprintf("%s %s", r.Method, r.URL.Path) {
+		case "GET /api/void/snakes/labels":
+			httpx.RespondOk([]api.Label{{Name: "latest", DeployID: 42}}, w)
+		case "GET /api/void/snakes/deploy/42":
+			httpx.RespondOk(&api.DeployDetail{Deploy: api.Deploy{ID: 42, Label: "latest"}}, w)
+		case "GET /api/void/snakes/deploy/41":
+			httpx.RespondOk(&api.DeployDetail{Deploy: api.Deploy{ID: 41, Label: "latest"}}, w)
+		case "POST /api/void/snakes/deploy/41/activate":
+			httpx.RespondOk(&api.Activation{DeployID: 41, URL: "https://snakes.void.dev"}, w)
+		default:
+			httpx.RespondBadRequest(fmt.Sprintf("unexpected %s %s", r.Method, r.URL.Path), w)
+		}
+	}))
+	defer mockServer.Close()
+
+	stdout, _, err := run(t, "--output", "json", "rollback", "--server", mockServer.URL, "--token", "token", "--org", "void", "--game", "snakes", "--to", "41", "--yes", "latest")
+	assert.NoError(t, err)
+
+	var summary map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &summary))
+	assert.Equal[any](t, float64(42), summary["from"])
+	assert.Equal[any](t, float64(41), summary["deployID"])
+	assert.Nil(t, summary["deployId"])
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestTimeoutAppliesToEveryCommand(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done() // never responds
+	}))
+	defer mockServer.Close()
+
+	for _, args := range [][]string{
+		{"orgs", "list"},
+		{"games", "list", "--org", "void"},
+		{"deploys", "list", "--org", "void", "--game", "snakes"},
+		{"labels", "list", "--org", "void", "--game", "snakes"},
+		{"promote", "--org", "void", "--game", "snakes", "qa", "latest"},
+		{"rollback", "--org", "void", "--game", "snakes", "--yes", "latest"},
+	} {
+		_, _, err := run(t, append(args, "--server", mockServer.URL, "--token", "token", "--retries", "0", "--timeout", "50ms")...)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), args)
+	}
+
+	_, _, err := run(t, "whoami", "--server", mockServer.URL, "--token", "token", "--timeout", "50ms")
+	assert.Regexp(t, "context deadline exceeded", err.Error())
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -2,18 +2,22 @@ module github.com/vaguevoid/cloud-cli
 
 go 1.24.2
 
+require (
+	github.com/BurntSushi/toml v1.4.1-0.20240526193622-a339e1f7089c
+	github.com/stretchr/testify v1.10.0
+	github.com/urfave/cli/v3 v3.3.3
+	github.com/zalando/go-keyring v0.2.6
+	github.com/zeebo/blake3 v0.2.4
+	golang.org/x/term v0.29.0
+)
+
 require (
 	al.essio.dev/pkg/shellescape v1.5.1 // indirect
-	github.com/BurntSushi/toml v1.4.1-0.20240526193622-a339e1f7089c // indirect
 	github.com/danieljoos/wincred v1.2.2 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/godbus/dbus/v5 v5.1.0 // indirect
 	github.com/klauspost/cpuid/v2 v2.0.12 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/stretchr/testify v1.10.0 // indirect
-	github.com/urfave/cli/v3 v3.3.3 // indirect
-	github.com/zalando/go-keyring v0.2.6 // indirect
-	github.com/zeebo/blake3 v0.2.4 // indirect
 	golang.org/x/exp/typeparams v0.0.0-20231108232855-2478ac86f678 // indirect
 	golang.org/x/mod v0.23.0 // indirect
 	golang.org/x/sync v0.11.0 // indirect
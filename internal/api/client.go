@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,11 +12,13 @@ import (
 	"path"
 
 	"github.com/vaguevoid/cloud-cli/internal/lib/httpx"
+	"github.com/vaguevoid/cloud-cli/internal/lib/progress"
 )
 
 type Client struct {
 	Endpoint *url.URL
 	Token    string
+	Retry    RetryPolicy
 	httpc    *http.Client
 }
 
@@ -30,6 +33,7 @@ func NewClient(server string, token string) (*Client, error) {
 	return &Client{
 		Endpoint: url,
 		Token:    token,
+		Retry:    NoRetry(),
 		httpc:    &http.Client{},
 	}, nil
 }
@@ -38,14 +42,33 @@ func NewClient(server string, token string) (*Client, error) {
 
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
 	req.Header.Set(httpx.HeaderAuthorization, fmt.Sprintf("Bearer %s", c.Token))
-	return c.httpc.Do(req)
+	return c.doWithRetry(req)
 }
 
 //-------------------------------------------------------------------------------------------------
 
 func (c *Client) Get(route string) (*http.Response, error) {
+	return c.GetContext(context.Background(), route)
+}
+
+func (c *Client) GetContext(ctx context.Context, route string) (*http.Response, error) {
+	url := c.URL(route)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func (c *Client) Delete(route string) (*http.Response, error) {
+	return c.DeleteContext(context.Background(), route)
+}
+
+func (c *Client) DeleteContext(ctx context.Context, route string) (*http.Response, error) {
 	url := c.URL(route)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -55,8 +78,12 @@ func (c *Client) Get(route string) (*http.Response, error) {
 //-------------------------------------------------------------------------------------------------
 
 func (c *Client) Post(route string, content io.Reader) (*http.Response, error) {
+	return c.PostContext(context.Background(), route, content)
+}
+
+func (c *Client) PostContext(ctx context.Context, route string, content io.Reader) (*http.Response, error) {
 	url := c.URL(route)
-	req, err := http.NewRequest(http.MethodPost, url, content)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, content)
 	if err != nil {
 		return nil, err
 	}
@@ -66,6 +93,16 @@ func (c *Client) Post(route string, content io.Reader) (*http.Response, error) {
 //-------------------------------------------------------------------------------------------------
 
 func (c *Client) PostJSON(route string, content any) (*http.Response, error) {
+	return c.PostJSONContext(context.Background(), route, content)
+}
+
+func (c *Client) PostJSONContext(ctx context.Context, route string, content any) (*http.Response, error) {
+	return c.PostJSONHeaders(ctx, route, content, nil)
+}
+
+// PostJSONHeaders adds header to the request, for endpoints that take
+// options (e.g. X-Deploy-Pinned) alongside the JSON body.
+func (c *Client) PostJSONHeaders(ctx context.Context, route string, content any, header http.Header) (*http.Response, error) {
 	url := c.URL(route)
 
 	data, err := json.Marshal(content)
@@ -73,11 +110,14 @@ func (c *Client) PostJSON(route string, content any) (*http.Response, error) {
 		return nil, err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
 	if err != nil {
 		return nil, err
 	}
 
+	for key, values := range header {
+		req.Header[key] = values
+	}
 	req.Header.Set(httpx.HeaderContentType, httpx.ContentTypeJSON)
 
 	return c.Do(req)
@@ -86,6 +126,18 @@ func (c *Client) PostJSON(route string, content any) (*http.Response, error) {
 //-------------------------------------------------------------------------------------------------
 
 func (c *Client) PostFILE(route string, filepath string) (*http.Response, error) {
+	return c.PostFILEContext(context.Background(), route, filepath)
+}
+
+func (c *Client) PostFILEContext(ctx context.Context, route string, filepath string) (*http.Response, error) {
+	return c.PostFILEProgress(ctx, route, filepath, nil)
+}
+
+// PostFILEProgress reports the number of bytes sent as the file is read by the
+// transport, when a request is retried the bytes already reported are reversed
+// with a negative count before the file is sent again. Uploading the same file
+// twice is harmless so the request is retried even after a network error.
+func (c *Client) PostFILEProgress(ctx context.Context, route string, filepath string, onProgress func(n int64)) (*http.Response, error) {
 	f, err := os.Open(filepath)
 	if err != nil {
 		return nil, err
@@ -97,13 +149,61 @@ func (c *Client) PostFILE(route string, filepath string) (*http.Response, error)
 		return nil, err
 	}
 
+	body := progress.NewReader(f, onProgress)
+
 	url := c.URL(route)
-	req, err := http.NewRequest(http.MethodPost, url, f)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
 	if err != nil {
 		return nil, err
 	}
 	req.ContentLength = fi.Size()
+	req.GetBody = func() (io.ReadCloser, error) {
+		if onProgress != nil && body.Count() > 0 {
+			onProgress(-body.Count())
+		}
+		f, err := os.Open(filepath)
+		if err != nil {
+			return nil, err
+		}
+		body = progress.NewReader(f, onProgress)
+		return body, nil
+	}
+	req.Header.Set(httpx.HeaderContentType, httpx.ContentTypeBytes)
+	markIdempotent(req)
+
+	return c.Do(req)
+}
+
+// PostStreamProgress is PostFILEProgress for content that is not a file on
+// disk, e.g. an archive entry. open is called again to rewind the content for
+// a retry, the request is not retried when that fails.
+func (c *Client) PostStreamProgress(ctx context.Context, route string, open func() (io.ReadCloser, error), size int64, onProgress func(n int64)) (*http.Response, error) {
+	content, err := open()
+	if err != nil {
+		return nil, err
+	}
+	body := progress.NewReader(content, onProgress)
+
+	url := c.URL(route)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		content.Close()
+		return nil, err
+	}
+	req.ContentLength = size
+	req.GetBody = func() (io.ReadCloser, error) {
+		if onProgress != nil && body.Count() > 0 {
+			onProgress(-body.Count())
+		}
+		content, err := open()
+		if err != nil {
+			return nil, err
+		}
+		body = progress.NewReader(content, onProgress)
+		return body, nil
+	}
 	req.Header.Set(httpx.HeaderContentType, httpx.ContentTypeBytes)
+	markIdempotent(req)
 
 	return c.Do(req)
 }
@@ -1,11 +1,16 @@
 package api_test
 
 import (
+	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/vaguevoid/cloud-cli/internal/api"
 	"github.com/vaguevoid/cloud-cli/internal/lib/httpx"
@@ -38,6 +43,27 @@ func TestClientGet(t *testing.T) {
 
 //-------------------------------------------------------------------------------------------------
 
+func TestClientDelete(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "/api/action/route", r.URL.Path)
+		assert.Equal(t, "Bearer header.payload.signature", r.Header.Get(httpx.HeaderAuthorization))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	api, err := api.NewClient(mockServer.URL, TestToken)
+	assert.Nil(t, err)
+	assert.NotNil(t, api)
+
+	resp, err := api.Delete("action/route")
+	assert.Nil(t, err)
+	assert.NotNil(t, resp)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	defer resp.Body.Close()
+}
+
+//-------------------------------------------------------------------------------------------------
+
 func TestClientPost(t *testing.T) {
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, http.MethodPost, r.Method)
@@ -84,6 +110,28 @@ func TestClientPostJSON(t *testing.T) {
 
 //-------------------------------------------------------------------------------------------------
 
+func TestClientPostJSONHeaders(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "true", r.Header.Get(httpx.HeaderXDeployPinned))
+		assert.Equal(t, httpx.ContentTypeJSON, r.Header.Get(httpx.HeaderContentType))
+		assert.RequestBodyEqual(t, `{"key":"value"}`, r)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	api, err := api.NewClient(mockServer.URL, TestToken)
+	assert.Nil(t, err)
+
+	header := http.Header{}
+	header.Set(httpx.HeaderXDeployPinned, "true")
+	resp, err := api.PostJSONHeaders(t.Context(), "action/route", map[string]string{"key": "value"}, header)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	defer resp.Body.Close()
+}
+
+//-------------------------------------------------------------------------------------------------
+
 func TestClientPostFILE(t *testing.T) {
 	content := "Hello World"
 	path := "path/to/hello.txt"
@@ -112,3 +160,350 @@ func TestClientPostFILE(t *testing.T) {
 }
 
 //-------------------------------------------------------------------------------------------------
+
+func retryingClient(t *testing.T, server string, attempts int) *api.Client {
+	client, err := api.NewClient(server, TestToken)
+	assert.Nil(t, err)
+	client.Retry = api.DefaultRetryPolicy(attempts - 1)
+	client.Retry.BaseDelay = time.Millisecond
+	client.Retry.MaxDelay = 50 * time.Millisecond
+	return client
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientDoesNotRetryByDefault(t *testing.T) {
+	attempts := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+
+	api, err := api.NewClient(mockServer.URL, TestToken)
+	assert.Nil(t, err)
+
+	resp, err := api.Get("action/route")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, 1, attempts)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientRetriesTransientFailures(t *testing.T) {
+	attempts := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		assert.Equal(t, "Bearer header.payload.signature", r.Header.Get(httpx.HeaderAuthorization))
+		if attempts < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+
+	api := retryingClient(t, mockServer.URL, 3)
+
+	resp, err := api.Get("action/route")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 3, attempts)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientGivesUpAfterMaxAttempts(t *testing.T) {
+	attempts := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	api := retryingClient(t, mockServer.URL, 3)
+
+	resp, err := api.Get("action/route")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, 3, attempts)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientDoesNotRetryClientErrors(t *testing.T) {
+	attempts := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+
+	api := retryingClient(t, mockServer.URL, 3)
+
+	resp, err := api.Get("action/route")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, 1, attempts)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+//-------------------------------------------------------------------------------------------------
+
+func hangupServer(t *testing.T, attempts *int) *httptest.Server {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*attempts++
+		io.Copy(io.Discard, r.Body)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		assert.Nil(t, err)
+		conn.Close()
+	}))
+	t.Cleanup(mockServer.Close)
+	return mockServer
+}
+
+func TestClientRetriesNetworkErrors(t *testing.T) {
+	attempts := 0
+	api := retryingClient(t, hangupServer(t, &attempts).URL, 3)
+
+	resp, err := api.Get("action/route")
+	assert.Nil(t, resp)
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, attempts)
+
+	attempts = 0
+	resp, err = api.PostJSON("action/route", []string{"foo", "bar"})
+	assert.Nil(t, resp)
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, attempts) // the server may have acted on it
+}
+
+func TestClientRetriesNetworkErrorsForUploads(t *testing.T) {
+	attempts := 0
+	api := retryingClient(t, hangupServer(t, &attempts).URL, 3)
+
+	resp, err := api.PostStreamProgress(t.Context(), "action/route", openString("Hello World"), 11, nil)
+	assert.Nil(t, resp)
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientHonorsRetryAfter(t *testing.T) {
+	attempts := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set(httpx.HeaderRetryAfter, "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+
+	api := retryingClient(t, mockServer.URL, 2)
+
+	start := time.Now()
+	resp, err := api.Get("action/route")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, attempts)
+	assert.True(t, time.Since(start) >= api.Retry.MaxDelay, "waited for Retry-After (capped at MaxDelay)")
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientDoesNotRetryServerErrorsForPost(t *testing.T) {
+	attempts := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer mockServer.Close()
+
+	api := retryingClient(t, mockServer.URL, 3)
+
+	resp, err := api.PostJSON("action/route", []string{"foo", "bar"})
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, 1, attempts)
+
+	attempts = 0
+	resp, err = api.Get("action/route")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, 3, attempts)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientRetryRewindsJSONBody(t *testing.T) {
+	attempts := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		assert.RequestBodyEqual(t, `["foo","bar"]`, r)
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+
+	api := retryingClient(t, mockServer.URL, 2)
+
+	resp, err := api.PostJSON("action/route", []string{"foo", "bar"})
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, attempts)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientRetryRewindsFileBody(t *testing.T) {
+	content := "Hello World"
+	path := "path/to/hello.txt"
+
+	tmp := mock.TempDir(t)
+	tmp.AddTextFile(t, path, content)
+
+	attempts := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		assert.RequestBodyEqual(t, content, r)
+		if attempts == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+
+	api := retryingClient(t, mockServer.URL, 2)
+
+	resp, err := api.PostFILE("action/route", filepath.Join(tmp.Dir, path))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, attempts)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientPostFILEProgress(t *testing.T) {
+	content := "Hello World"
+	path := "path/to/hello.txt"
+
+	tmp := mock.TempDir(t)
+	tmp.AddTextFile(t, path, content)
+
+	attempts := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		assert.RequestBodyEqual(t, content, r)
+		if attempts == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+
+	api := retryingClient(t, mockServer.URL, 2)
+
+	var mutex sync.Mutex
+	var sent, rewound int64
+	resp, err := api.PostFILEProgress(t.Context(), "action/route", filepath.Join(tmp.Dir, path), func(n int64) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		sent += n
+		if n < 0 {
+			rewound += n
+		}
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, int64(len(content)), sent)
+	assert.Equal(t, -int64(len(content)), rewound)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func openString(content string) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(content)), nil
+	}
+}
+
+func TestClientPostStreamProgress(t *testing.T) {
+	content := "Hello World"
+
+	attempts := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		assert.RequestHeaderEqual(t, httpx.ContentTypeBytes, httpx.HeaderContentType, r)
+		assert.RequestBodyEqual(t, content, r)
+		if attempts == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+
+	api := retryingClient(t, mockServer.URL, 2)
+
+	var mutex sync.Mutex
+	var sent int64
+	resp, err := api.PostStreamProgress(t.Context(), "action/route", openString(content), int64(len(content)), func(n int64) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		sent += n
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, attempts)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, int64(len(content)), sent)
+}
+
+func TestClientPostStreamProgressReadOnce(t *testing.T) {
+	attempts := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		assert.RequestBodyEqual(t, "Hello World", r)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	api := retryingClient(t, mockServer.URL, 3)
+
+	opened := false
+	resp, err := api.PostStreamProgress(t.Context(), "action/route", func() (io.ReadCloser, error) {
+		if opened {
+			return nil, errors.New("already read")
+		}
+		opened = true
+		return io.NopCloser(strings.NewReader("Hello World")), nil
+	}, 11, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, 1, attempts) // the content can't be sent again
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientGetContext(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	api := retryingClient(t, mockServer.URL, 3)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	resp, err := api.GetContext(ctx, "action/route")
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/vaguevoid/cloud-cli/internal/lib/httpx"
+)
+
+//=================================================================================================
+// DEPLOYS
+//=================================================================================================
+
+type Deploy struct {
+	ID        int64     `json:"id"`
+	Label     string    `json:"label,omitempty"`
+	Active    bool      `json:"active"` // currently served under its label
+	Slug      string    `json:"slug"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"createdAt"`
+	Author    string    `json:"author,omitempty"`
+	Files     int       `json:"files"`
+	Size      int64     `json:"size"`
+	Pinned    bool      `json:"pinned"`
+	Protected bool      `json:"protected"` // requires a password to play
+}
+
+type DeployDetail struct {
+	Deploy
+	Manifest []ManifestEntry `json:"manifest"`
+}
+
+type Activation struct {
+	DeployID int64  `json:"deployId"`
+	Slug     string `json:"slug"`
+	URL      string `json:"url"`
+}
+
+type ManifestEntry struct {
+	Path          string `json:"path"`
+	Blake3        string `json:"blake3"`
+	ContentLength int64  `json:"contentLength"`
+}
+
+// ListDeploys returns the deploys of a game, most recent first.
+func (c *Client) ListDeploys(ctx context.Context, org string, game string) ([]Deploy, error) {
+	var deploys []Deploy
+	if err := c.getJSON(ctx, c.Route(org, game, "deploy"), &deploys); err != nil {
+		return nil, err
+	}
+	return deploys, nil
+}
+
+func (c *Client) GetDeploy(ctx context.Context, org string, game string, deployID int64) (*DeployDetail, error) {
+	var deploy DeployDetail
+	if err := c.getJSON(ctx, c.Route(org, game, "deploy", deployID), &deploy); err != nil {
+		return nil, about(err, "deploy %d", deployID)
+	}
+	return &deploy, nil
+}
+
+// ActivateDeploy serves a deploy under label, or under the label it was
+// deployed with when label is empty.
+func (c *Client) ActivateDeploy(ctx context.Context, org string, game string, deployID int64, label string) (*Activation, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL(c.Route(org, game, "deploy", deployID, "activate")), nil)
+	if err != nil {
+		return nil, err
+	}
+	if label != "" {
+		req.Header.Set(httpx.HeaderXDeployLabel, label)
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, about(NewError(resp), "deploy %d", deployID)
+	}
+	var activation Activation
+	if err := json.NewDecoder(resp.Body).Decode(&activation); err != nil {
+		return nil, err
+	}
+	return &activation, nil
+}
+
+// PinDeploy exempts a deploy from automatic cleanup.
+func (c *Client) PinDeploy(ctx context.Context, org string, game string, deployID int64) (*Deploy, error) {
+	var deploy Deploy
+	if err := c.postJSON(ctx, c.Route(org, game, "deploy", deployID, "pin"), nil, &deploy); err != nil {
+		return nil, about(err, "deploy %d", deployID)
+	}
+	return &deploy, nil
+}
+
+func (c *Client) UnpinDeploy(ctx context.Context, org string, game string, deployID int64) (*Deploy, error) {
+	var deploy Deploy
+	if err := c.postJSON(ctx, c.Route(org, game, "deploy", deployID, "unpin"), nil, &deploy); err != nil {
+		return nil, about(err, "deploy %d", deployID)
+	}
+	return &deploy, nil
+}
+
+func (c *Client) SetDeployPassword(ctx context.Context, org string, game string, deployID int64, password string) error {
+	err := c.postJSON(ctx, c.Route(org, game, "deploy", deployID, "password"), map[string]string{"password": password}, nil)
+	return about(err, "deploy %d", deployID)
+}
+
+func (c *Client) ClearDeployPassword(ctx context.Context, org string, game string, deployID int64) error {
+	return about(c.delete(ctx, c.Route(org, game, "deploy", deployID, "password")), "deploy %d", deployID)
+}
+
+func (c *Client) DeleteDeploy(ctx context.Context, org string, game string, deployID int64) error {
+	return about(c.delete(ctx, c.Route(org, game, "deploy", deployID)), "deploy %d", deployID)
+}
+
+//-------------------------------------------------------------------------------------------------
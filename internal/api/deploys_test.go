@@ -0,0 +1,163 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/vaguevoid/cloud-cli/internal/api"
+	"github.com/vaguevoid/cloud-cli/internal/lib/httpx"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientListDeploys(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/void/snakes/deploy", r.URL.Path)
+		w.Write([]byte(`[{"id":42,"label":"latest","slug":"a1b2c3","url":"https://play.void.dev/void/snakes/latest","createdAt":"2025-01-02T03:04:05Z","author":"Jake","files":3,"size":1024,"pinned":true}]`))
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, TestToken)
+	assert.Nil(t, err)
+
+	deploys, err := client.ListDeploys(context.Background(), "void", "snakes")
+	assert.Nil(t, err)
+	assert.Equal(t, []api.Deploy{{
+		ID:        42,
+		Label:     "latest",
+		Slug:      "a1b2c3",
+		URL:       "https://play.void.dev/void/snakes/latest",
+		CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Author:    "Jake",
+		Files:     3,
+		Size:      1024,
+		Pinned:    true,
+	}}, deploys)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientGetDeploy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/void/snakes/deploy/42", r.URL.Path)
+		w.Write([]byte(`{"id":42,"slug":"a1b2c3","manifest":[{"path":"index.html","blake3":"abc","contentLength":12}]}`))
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, TestToken)
+	assert.Nil(t, err)
+
+	deploy, err := client.GetDeploy(context.Background(), "void", "snakes", 42)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(42), deploy.ID)
+	assert.Equal(t, "a1b2c3", deploy.Slug)
+	assert.Equal(t, []api.ManifestEntry{{Path: "index.html", Blake3: "abc", ContentLength: 12}}, deploy.Manifest)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientActivateDeploy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/void/snakes/deploy/41/activate", r.URL.Path)
+		label := r.Header.Get(httpx.HeaderXDeployLabel)
+		w.Write([]byte(`{"deployId":41,"slug":"zz9","url":"https://play.void.dev/void/snakes/` + label + `"}`))
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, TestToken)
+	assert.Nil(t, err)
+
+	activation, err := client.ActivateDeploy(context.Background(), "void", "snakes", 41, "latest")
+	assert.Nil(t, err)
+	assert.Equal(t, &api.Activation{DeployID: 41, Slug: "zz9", URL: "https://play.void.dev/void/snakes/latest"}, activation)
+
+	activation, err = client.ActivateDeploy(context.Background(), "void", "snakes", 41, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "https://play.void.dev/void/snakes/", activation.URL)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientPinDeploy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		switch r.URL.Path {
+		case "/api/void/snakes/deploy/42/pin":
+			w.Write([]byte(`{"id":42,"pinned":true}`))
+		case "/api/void/snakes/deploy/42/unpin":
+			w.Write([]byte(`{"id":42,"pinned":false}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, TestToken)
+	assert.Nil(t, err)
+
+	deploy, err := client.PinDeploy(context.Background(), "void", "snakes", 42)
+	assert.Nil(t, err)
+	assert.True(t, deploy.Pinned)
+
+	deploy, err = client.UnpinDeploy(context.Background(), "void", "snakes", 42)
+	assert.Nil(t, err)
+	assert.False(t, deploy.Pinned)
+
+	_, err = client.PinDeploy(context.Background(), "void", "snakes", 43)
+	assert.True(t, api.IsNotFound(err))
+	assert.Equal(t, "deploy 43", api.ErrorResource(err))
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientDeployPassword(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/void/snakes/deploy/42/password", r.URL.Path)
+		switch r.Method {
+		case http.MethodPost:
+			assert.RequestJSONEqual(t, map[string]string{"password": "s3cret"}, r)
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, TestToken)
+	assert.Nil(t, err)
+
+	assert.Nil(t, client.SetDeployPassword(context.Background(), "void", "snakes", 42, "s3cret"))
+	assert.Nil(t, client.ClearDeployPassword(context.Background(), "void", "snakes", 42))
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientDeleteDeploy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		switch r.URL.Path {
+		case "/api/void/snakes/deploy/42":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, TestToken)
+	assert.Nil(t, err)
+
+	assert.Nil(t, client.DeleteDeploy(context.Background(), "void", "snakes", 42))
+	err = client.DeleteDeploy(context.Background(), "void", "snakes", 43)
+	assert.True(t, api.IsNotFound(err))
+	assert.Equal(t, "deploy 43", api.ErrorResource(err))
+}
+
+//-------------------------------------------------------------------------------------------------
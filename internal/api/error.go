@@ -0,0 +1,142 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/vaguevoid/cloud-cli/internal/lib/httpx"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+const (
+	ErrorCodeQuotaExceeded = "quota_exceeded"
+	maxErrorBody           = 64 * 1024
+)
+
+// Error is a non-successful response from the server, decoded from a JSON
+// error body when there is one, otherwise the body text is the Message.
+// Resource names what the request was about (e.g. "deploy 42") when the
+// client knows it, so that a 404 can say what was not found.
+type Error struct {
+	Status    int          `json:"status"`
+	Code      string       `json:"code,omitempty"`
+	Message   string       `json:"message,omitempty"`
+	RequestID string       `json:"requestId,omitempty"`
+	Fields    []FieldError `json:"fields,omitempty"`
+	Resource  string       `json:"resource,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	var sb strings.Builder
+	if e.Message != "" {
+		fmt.Fprintf(&sb, "unexpected status code %d: %s", e.Status, e.Message)
+	} else if e.Status == http.StatusUnauthorized {
+		sb.WriteString("unauthorized")
+	} else {
+		fmt.Fprintf(&sb, "unexpected status code %d", e.Status)
+	}
+	for _, field := range e.Fields {
+		fmt.Fprintf(&sb, "\n  %s: %s", field.Field, field.Message)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&sb, " (request %s)", e.RequestID)
+	}
+	return sb.String()
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func NewError(resp *http.Response) *Error {
+	e := &Error{
+		Status:    resp.StatusCode,
+		RequestID: resp.Header.Get(httpx.HeaderXRequestID),
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	text := strings.TrimSpace(string(body))
+	if text == "" {
+		return e
+	}
+
+	var decoded struct {
+		Code      string       `json:"code"`
+		Message   string       `json:"message"`
+		Error     string       `json:"error"`
+		RequestID string       `json:"requestId"`
+		Fields    []FieldError `json:"fields"`
+	}
+	if strings.HasPrefix(text, "{") && json.Unmarshal(body, &decoded) == nil {
+		e.Code = decoded.Code
+		e.Message = decoded.Message
+		if e.Message == "" {
+			e.Message = decoded.Error
+		}
+		if decoded.RequestID != "" {
+			e.RequestID = decoded.RequestID
+		}
+		e.Fields = decoded.Fields
+	} else {
+		e.Message = text
+	}
+	return e
+}
+
+func about(err error, format string, args ...any) error {
+	var e *Error
+	if errors.As(err, &e) && e.Resource == "" {
+		e.Resource = fmt.Sprintf(format, args...)
+	}
+	return err
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func StatusCode(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Status
+	}
+	return 0
+}
+
+func ErrorResource(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Resource
+	}
+	return ""
+}
+
+func IsUnauthorized(err error) bool {
+	return StatusCode(err) == http.StatusUnauthorized
+}
+
+func IsForbidden(err error) bool {
+	return StatusCode(err) == http.StatusForbidden
+}
+
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+func IsQuotaExceeded(err error) bool {
+	var e *Error
+	if !errors.As(err, &e) {
+		return false
+	}
+	return e.Code == ErrorCodeQuotaExceeded ||
+		e.Status == http.StatusPaymentRequired ||
+		e.Status == http.StatusInsufficientStorage
+}
+
+//-------------------------------------------------------------------------------------------------
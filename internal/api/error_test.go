@@ -0,0 +1,89 @@
+package api_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/vaguevoid/cloud-cli/internal/api"
+	"github.com/vaguevoid/cloud-cli/internal/lib/httpx"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+func makeResponse(status int, body string, headers ...string) *http.Response {
+	resp := &http.Response{
+		StatusCode: status,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		resp.Header.Set(headers[i], headers[i+1])
+	}
+	return resp
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestErrorFromJSON(t *testing.T) {
+	err := api.NewError(makeResponse(http.StatusUnprocessableEntity,
+		`{"code":"invalid","message":"manifest is invalid","requestId":"req-123","fields":[{"field":"path","message":"is required"}]}`))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, err.Status)
+	assert.Equal(t, "invalid", err.Code)
+	assert.Equal(t, "manifest is invalid", err.Message)
+	assert.Equal(t, "req-123", err.RequestID)
+	assert.Equal(t, []api.FieldError{{Field: "path", Message: "is required"}}, err.Fields)
+	assert.Equal(t, "unexpected status code 422: manifest is invalid\n  path: is required (request req-123)", err.Error())
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestErrorFromJSONErrorField(t *testing.T) {
+	err := api.NewError(makeResponse(http.StatusBadRequest, `{"error":"uh oh"}`, httpx.HeaderXRequestID, "req-456"))
+	assert.Equal(t, "uh oh", err.Message)
+	assert.Equal(t, "req-456", err.RequestID)
+	assert.Equal(t, "unexpected status code 400: uh oh (request req-456)", err.Error())
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestErrorFromText(t *testing.T) {
+	err := api.NewError(makeResponse(http.StatusBadRequest, "uh oh, manifest was empty\n"))
+	assert.Equal(t, "uh oh, manifest was empty", err.Message)
+	assert.Equal(t, "unexpected status code 400: uh oh, manifest was empty", err.Error())
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestErrorWithoutBody(t *testing.T) {
+	assert.Equal(t, "unexpected status code 500", api.NewError(makeResponse(http.StatusInternalServerError, "")).Error())
+	assert.Equal(t, "unauthorized", api.NewError(makeResponse(http.StatusUnauthorized, "")).Error())
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestErrorHelpers(t *testing.T) {
+	unauthorized := fmt.Errorf("wrapped: %w", api.NewError(makeResponse(http.StatusUnauthorized, "")))
+	forbidden := api.NewError(makeResponse(http.StatusForbidden, ""))
+	notFound := api.NewError(makeResponse(http.StatusNotFound, ""))
+	quota := api.NewError(makeResponse(http.StatusBadRequest, `{"code":"quota_exceeded"}`))
+	storage := api.NewError(makeResponse(http.StatusInsufficientStorage, ""))
+	other := fmt.Errorf("not an api error")
+
+	assert.True(t, api.IsUnauthorized(unauthorized))
+	assert.False(t, api.IsUnauthorized(notFound))
+	assert.True(t, api.IsForbidden(forbidden))
+	assert.True(t, api.IsNotFound(notFound))
+	assert.False(t, api.IsNotFound(other))
+	assert.True(t, api.IsQuotaExceeded(quota))
+	assert.True(t, api.IsQuotaExceeded(storage))
+	assert.False(t, api.IsQuotaExceeded(other))
+	assert.Equal(t, http.StatusUnauthorized, api.StatusCode(unauthorized))
+	assert.Equal(t, 0, api.StatusCode(other))
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"context"
+	"time"
+)
+
+//=================================================================================================
+// LABELS
+//=================================================================================================
+
+type Label struct {
+	Name      string    `json:"name"`
+	DeployID  int64     `json:"deployId"` // the deploy currently served under the label
+	Slug      string    `json:"slug"`
+	URL       string    `json:"url"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ListLabels returns the labels of a game, sorted by name.
+func (c *Client) ListLabels(ctx context.Context, org string, game string) ([]Label, error) {
+	var labels []Label
+	if err := c.getJSON(ctx, c.Route(org, game, "labels"), &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// SetLabel serves an existing deploy under label, creating the label if needed.
+func (c *Client) SetLabel(ctx context.Context, org string, game string, label string, deployID int64) (*Label, error) {
+	var result Label
+	if err := c.postJSON(ctx, c.Route(org, game, "labels", label), map[string]int64{"deployId": deployID}, &result); err != nil {
+		return nil, about(err, "deploy %d", deployID)
+	}
+	return &result, nil
+}
+
+func (c *Client) RenameLabel(ctx context.Context, org string, game string, label string, name string) (*Label, error) {
+	var result Label
+	if err := c.postJSON(ctx, c.Route(org, game, "labels", label, "rename"), map[string]string{"name": name}, &result); err != nil {
+		return nil, about(err, "label %s", label)
+	}
+	return &result, nil
+}
+
+// DeleteLabel stops serving anything under label, the deploy behind it is kept.
+func (c *Client) DeleteLabel(ctx context.Context, org string, game string, label string) error {
+	return about(c.delete(ctx, c.Route(org, game, "labels", label)), "label %s", label)
+}
+
+//-------------------------------------------------------------------------------------------------
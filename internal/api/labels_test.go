@@ -0,0 +1,102 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/vaguevoid/cloud-cli/internal/api"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientListLabels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/void/snakes/labels", r.URL.Path)
+		w.Write([]byte(`[{"name":"latest","deployId":42,"slug":"a1b2c3","url":"https://play.void.dev/void/snakes/latest","updatedAt":"2025-01-02T03:04:05Z"}]`))
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, TestToken)
+	assert.Nil(t, err)
+
+	labels, err := client.ListLabels(context.Background(), "void", "snakes")
+	assert.Nil(t, err)
+	assert.Equal(t, []api.Label{{
+		Name:      "latest",
+		DeployID:  42,
+		Slug:      "a1b2c3",
+		URL:       "https://play.void.dev/void/snakes/latest",
+		UpdatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}}, labels)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientSetLabel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/void/snakes/labels/latest", r.URL.Path)
+		assert.RequestJSONEqual(t, map[string]int64{"deployId": 42}, r)
+		w.Write([]byte(`{"name":"latest","deployId":42,"url":"https://play.void.dev/void/snakes/latest"}`))
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, TestToken)
+	assert.Nil(t, err)
+
+	label, err := client.SetLabel(context.Background(), "void", "snakes", "latest", 42)
+	assert.Nil(t, err)
+	assert.Equal(t, "latest", label.Name)
+	assert.Equal(t, int64(42), label.DeployID)
+	assert.Equal(t, "https://play.void.dev/void/snakes/latest", label.URL)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientRenameLabel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/void/snakes/labels/staging/rename", r.URL.Path)
+		assert.RequestJSONEqual(t, map[string]string{"name": "qa"}, r)
+		w.Write([]byte(`{"name":"qa","deployId":41}`))
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, TestToken)
+	assert.Nil(t, err)
+
+	label, err := client.RenameLabel(context.Background(), "void", "snakes", "staging", "qa")
+	assert.Nil(t, err)
+	assert.Equal(t, "qa", label.Name)
+	assert.Equal(t, int64(41), label.DeployID)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientDeleteLabel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		switch r.URL.Path {
+		case "/api/void/snakes/labels/staging":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, TestToken)
+	assert.Nil(t, err)
+
+	assert.Nil(t, client.DeleteLabel(context.Background(), "void", "snakes", "staging"))
+	err = client.DeleteLabel(context.Background(), "void", "snakes", "qa")
+	assert.True(t, api.IsNotFound(err))
+	assert.Equal(t, "label qa", api.ErrorResource(err))
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+//=================================================================================================
+// ORGANIZATIONS AND GAMES
+//=================================================================================================
+
+type Organization struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type Game struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// ListOrganizations returns the organizations the current user belongs to.
+func (c *Client) ListOrganizations(ctx context.Context) ([]Organization, error) {
+	var orgs []Organization
+	if err := c.getJSON(ctx, c.Route("account", "organizations"), &orgs); err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
+
+func (c *Client) ListGames(ctx context.Context, org string) ([]Game, error) {
+	var games []Game
+	if err := c.getJSON(ctx, c.Route(org, "games"), &games); err != nil {
+		return nil, about(err, "organization %s", org)
+	}
+	return games, nil
+}
+
+func (c *Client) CreateGame(ctx context.Context, org string, name string) (*Game, error) {
+	var game Game
+	if err := c.postJSON(ctx, c.Route(org, "games"), map[string]string{"name": name}, &game); err != nil {
+		return nil, about(err, "organization %s", org)
+	}
+	return &game, nil
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func (c *Client) getJSON(ctx context.Context, route string, v any) error {
+	resp, err := c.GetContext(ctx, route)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return NewError(resp)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// postJSON posts body (nothing when nil) and decodes the response into v
+// (unless nil), any 2xx status is a success.
+func (c *Client) postJSON(ctx context.Context, route string, body any, v any) error {
+	var resp *http.Response
+	var err error
+	if body == nil {
+		resp, err = c.PostContext(ctx, route, nil)
+	} else {
+		resp, err = c.PostJSONContext(ctx, route, body)
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return NewError(resp)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (c *Client) delete(ctx context.Context, route string) error {
+	resp, err := c.DeleteContext(ctx, route)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return NewError(resp)
+	}
+	return nil
+}
+
+//-------------------------------------------------------------------------------------------------
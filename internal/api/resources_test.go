@@ -0,0 +1,88 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vaguevoid/cloud-cli/internal/api"
+	"github.com/vaguevoid/cloud-cli/internal/lib/httpx"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientListOrganizations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/account/organizations", r.URL.Path)
+		assert.Equal(t, "Bearer header.payload.signature", r.Header.Get(httpx.HeaderAuthorization))
+		w.Write([]byte(`[{"id":1,"name":"Void","slug":"void"},{"id":2,"name":"Atari","slug":"atari"}]`))
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, TestToken)
+	assert.Nil(t, err)
+
+	orgs, err := client.ListOrganizations(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []api.Organization{
+		{ID: 1, Name: "Void", Slug: "void"},
+		{ID: 2, Name: "Atari", Slug: "atari"},
+	}, orgs)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientListGames(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/void/games", r.URL.Path)
+		w.Write([]byte(`[{"id":42,"name":"Snakes","slug":"snakes"}]`))
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, TestToken)
+	assert.Nil(t, err)
+
+	games, err := client.ListGames(context.Background(), "void")
+	assert.Nil(t, err)
+	assert.Equal(t, []api.Game{{ID: 42, Name: "Snakes", Slug: "snakes"}}, games)
+}
+
+func TestClientListGamesNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, TestToken)
+	assert.Nil(t, err)
+
+	games, err := client.ListGames(context.Background(), "unknown")
+	assert.Nil(t, games)
+	assert.True(t, api.IsNotFound(err))
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestClientCreateGame(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/void/games", r.URL.Path)
+		assert.RequestJSONEqual(t, map[string]string{"name": "Tetris"}, r)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":43,"name":"Tetris","slug":"tetris"}`))
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, TestToken)
+	assert.Nil(t, err)
+
+	game, err := client.CreateGame(context.Background(), "void", "Tetris")
+	assert.Nil(t, err)
+	assert.Equal(t, &api.Game{ID: 43, Name: "Tetris", Slug: "tetris"}, game)
+}
+
+//-------------------------------------------------------------------------------------------------
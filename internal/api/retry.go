@@ -0,0 +1,168 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vaguevoid/cloud-cli/internal/lib/httpx"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+const (
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	DefaultRetryMaxDelay  = 30 * time.Second
+)
+
+// RetryPolicy controls how Client.Do retries failed requests. Delays grow
+// exponentially from BaseDelay up to MaxDelay with jitter, a Retry-After header
+// from the server overrides the computed delay (still capped at MaxDelay).
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Retryable   func(req *http.Request, resp *http.Response, err error) bool
+}
+
+func NoRetry() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+func DefaultRetryPolicy(retries int) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: retries + 1,
+		BaseDelay:   DefaultRetryBaseDelay,
+		MaxDelay:    DefaultRetryMaxDelay,
+		Retryable:   IsRetryable,
+	}
+}
+
+// IsRetryable retries responses that say the request was not handled (408,
+// 429 and 503) for any method. A network error, 500, 502 or 504 may come after
+// the server acted on the request, so those are only retried when the request
+// is idempotent.
+func IsRetryable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		return isIdempotent(req)
+	}
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusGatewayTimeout:
+		return isIdempotent(req)
+	default:
+		return false
+	}
+}
+
+// isIdempotent follows net/http, a request can be marked idempotent with an
+// Idempotency-Key header, a nil value marks it without sending the header.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	_, ok := req.Header[httpx.HeaderIdempotencyKey]
+	return ok
+}
+
+func markIdempotent(req *http.Request) {
+	if _, ok := req.Header[httpx.HeaderIdempotencyKey]; !ok {
+		req.Header[httpx.HeaderIdempotencyKey] = nil
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func (c *Client) doWithRetry(req *http.Request) (*http.Response, error) {
+	policy := c.Retry
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.httpc.Do(req)
+
+		if attempt >= policy.MaxAttempts || !retryable(req, resp, err) {
+			return resp, err
+		}
+
+		next, rewindErr := rewind(req)
+		if rewindErr != nil {
+			return resp, err // body can't be replayed, give up with the original outcome
+		}
+
+		delay := policy.delay(attempt, resp)
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+
+		req = next
+	}
+}
+
+func rewind(req *http.Request) (*http.Request, error) {
+	next := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return next, nil
+	}
+	if req.GetBody == nil {
+		return nil, errors.New("request body cannot be rewound")
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	next.Body = body
+	return next, nil
+}
+
+func (p RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	delay := p.MaxDelay
+	if attempt < 32 {
+		delay = min(p.BaseDelay<<(attempt-1), p.MaxDelay)
+	}
+	delay = delay/2 + rand.N(delay/2+1)
+
+	if resp != nil {
+		if after, ok := retryAfter(resp.Header.Get(httpx.HeaderRetryAfter)); ok {
+			delay = min(after, p.MaxDelay)
+		}
+	}
+	return delay
+}
+
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		return max(time.Until(when), 0), true
+	}
+	return 0, false
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,158 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/vaguevoid/cloud-cli/internal/api"
+)
+
+//=================================================================================================
+// DEVICE AUTHORIZATION
+//=================================================================================================
+
+const (
+	DefaultDevicePollInterval = 5 * time.Second
+	DeviceSlowDownInterval    = 5 * time.Second
+)
+
+// DeviceCode is issued by the server at the start of a device login, the user
+// visits VerificationURL on any device and enters UserCode to approve it.
+type DeviceCode struct {
+	DeviceCode              string `json:"deviceCode"`
+	UserCode                string `json:"userCode"`
+	VerificationURL         string `json:"verificationUri"`
+	VerificationURLComplete string `json:"verificationUriComplete,omitempty"`
+	ExpiresIn               int    `json:"expiresIn"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+const (
+	deviceAuthorizationPending = "authorization_pending"
+	deviceSlowDown             = "slow_down"
+	deviceAccessDenied         = "access_denied"
+	deviceExpiredToken         = "expired_token"
+)
+
+//=================================================================================================
+// PRIVATE IMPLEMENTATION
+//=================================================================================================
+
+func (cmd *LoginCommand) deviceLogin(timer *loginTimer) (string, error) {
+	code, err := cmd.requestDeviceCode(timer.Context)
+	if err != nil {
+		return "", err
+	}
+
+	if cmd.OnDeviceCode != nil {
+		cmd.OnDeviceCode(code)
+	}
+
+	ctx := timer.Context
+	if code.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(code.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+
+	interval := DefaultDevicePollInterval
+	slowDown := DeviceSlowDownInterval
+	if cmd.pollInterval > 0 {
+		interval = cmd.pollInterval
+		slowDown = cmd.pollInterval
+	} else if code.Interval > 0 {
+		interval = time.Duration(code.Interval) * time.Second
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(timer.Context.Err(), context.Canceled) {
+				return "", timer.Context.Err()
+			} else if timer.Context.Err() != nil {
+				return "", fmt.Errorf("login timed out")
+			}
+			return "", fmt.Errorf("device code expired, please login again")
+		case <-time.After(interval):
+		}
+
+		jwt, status, err := cmd.pollDeviceToken(ctx, code.DeviceCode)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			continue // let the select above report why
+		case err != nil:
+			return "", err
+		case status == deviceAuthorizationPending:
+			continue
+		case status == deviceSlowDown:
+			interval += slowDown
+		case status == deviceAccessDenied:
+			return "", fmt.Errorf("login was denied")
+		case status == deviceExpiredToken:
+			return "", fmt.Errorf("device code expired, please login again")
+		default:
+			return jwt, nil
+		}
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func (cmd *LoginCommand) requestDeviceCode(ctx context.Context) (*DeviceCode, error) {
+	resp, err := postJSON(ctx, cmd.Server, "api/account/device/code", map[string]string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, api.NewError(resp)
+	}
+
+	var code DeviceCode
+	err = json.NewDecoder(resp.Body).Decode(&code)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected JSON response: %s", err)
+	} else if code.DeviceCode == "" || code.UserCode == "" || code.VerificationURL == "" {
+		return nil, fmt.Errorf("unexpected device code response")
+	}
+	return &code, nil
+}
+
+// pollDeviceToken returns the JWT once the user has approved the login,
+// otherwise the error code returned by the server (e.g. authorization_pending).
+func (cmd *LoginCommand) pollDeviceToken(ctx context.Context, deviceCode string) (string, string, error) {
+	resp, err := postJSON(ctx, cmd.Server, "api/account/device/token", map[string]string{"deviceCode": deviceCode})
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := api.NewError(resp)
+		for _, status := range []string{apiErr.Code, apiErr.Message} {
+			switch status {
+			case deviceAuthorizationPending, deviceSlowDown, deviceAccessDenied, deviceExpiredToken:
+				return "", status, nil
+			}
+		}
+		return "", "", apiErr
+	}
+
+	var token struct {
+		JWT string `json:"jwt"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	if err != nil {
+		return "", "", fmt.Errorf("unexpected JSON response: %s", err)
+	} else if token.JWT == "" {
+		return "", "", fmt.Errorf("missing jwt in device token response")
+	}
+	return token.JWT, "", nil
+}
+
+//-------------------------------------------------------------------------------------------------
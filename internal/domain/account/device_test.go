@@ -0,0 +1,126 @@
+package account_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vaguevoid/cloud-cli/internal/domain/account"
+	"github.com/vaguevoid/cloud-cli/internal/lib/httpx"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+	"github.com/vaguevoid/cloud-cli/internal/test/mock"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+var TestDeviceCode = &account.DeviceCode{
+	DeviceCode:      "device-code",
+	UserCode:        "ABCD-EFGH",
+	VerificationURL: "https://test.void.dev/device",
+	ExpiresIn:       60,
+	Interval:        5,
+}
+
+// deviceServer answers the device code request, then answers each token poll
+// with the next of responses (a status code to send, or a jwt on success).
+func deviceServer(t *testing.T, responses ...string) (*httptest.Server, *int) {
+	var mutex sync.Mutex
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		switch r.URL.Path {
+		case "/api/account/device/code":
+			assert.RequestMethodEqual(t, http.MethodPost, r)
+			httpx.RespondOk(TestDeviceCode, w)
+		case "/api/account/device/token":
+			assert.RequestJSONEqual(t, map[string]string{"deviceCode": "device-code"}, r)
+			response := responses[min(polls, len(responses)-1)]
+			polls++
+			switch response {
+			case "authorization_pending", "slow_down", "access_denied", "expired_token":
+				httpx.RespondBadRequest(map[string]string{"error": response}, w)
+			default:
+				httpx.RespondOk(map[string]string{"jwt": response}, w)
+			}
+		case "/api/account/me":
+			assert.RequestHeaderEqual(t, "Bearer header.payload.signature", httpx.HeaderAuthorization, r)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"id":100,"name":"TestDeviceLogin"}`))
+		default:
+			httpx.RespondBadRequest("unexpected "+r.URL.Path, w)
+		}
+	}))
+	return server, &polls
+}
+
+func deviceLogin(t *testing.T, server string, keyring *mock.MockKeyring) (*account.User, *account.DeviceCode, error) {
+	var shown *account.DeviceCode
+	user, err := account.Login(t.Context(), account.WithPollInterval(&account.LoginCommand{
+		Server:  server,
+		Keyring: keyring,
+		Device:  true,
+		OnDeviceCode: func(code *account.DeviceCode) {
+			shown = code
+		},
+	}, time.Millisecond))
+	return user, shown, err
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestDeviceLogin(t *testing.T) {
+	server, polls := deviceServer(t, "authorization_pending", "slow_down", "authorization_pending", "header.payload.signature")
+	keyring := mock.Keyring()
+
+	user, code, err := deviceLogin(t, server.URL, keyring)
+	assert.NoError(t, err)
+	assert.Equal(t, &account.User{ID: 100, Name: "TestDeviceLogin"}, user)
+	assert.Equal(t, TestDeviceCode, code)
+	assert.Equal(t, 4, *polls)
+
+	jwt, ok := keyring.Get(httpx.ParamJWT)
+	assert.True(t, ok)
+	assert.Equal(t, "header.payload.signature", jwt)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestDeviceLoginDenied(t *testing.T) {
+	server, _ := deviceServer(t, "authorization_pending", "access_denied")
+	keyring := mock.Keyring()
+
+	user, _, err := deviceLogin(t, server.URL, keyring)
+	assert.Nil(t, user)
+	assert.Error(t, "login was denied", err)
+	assert.False(t, keyring.Has(httpx.ParamJWT))
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestDeviceLoginExpired(t *testing.T) {
+	server, _ := deviceServer(t, "expired_token")
+
+	user, _, err := deviceLogin(t, server.URL, mock.Keyring())
+	assert.Nil(t, user)
+	assert.Error(t, "device code expired, please login again", err)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestDeviceLoginTimeout(t *testing.T) {
+	server, _ := deviceServer(t, "authorization_pending")
+
+	user, err := account.Login(t.Context(), account.WithPollInterval(&account.LoginCommand{
+		Server:  server.URL,
+		Keyring: mock.Keyring(),
+		Device:  true,
+		Timeout: 50 * time.Millisecond,
+	}, time.Millisecond))
+	assert.Nil(t, user)
+	assert.Error(t, "login timed out", err)
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,10 @@
+package account
+
+import "time"
+
+// WithPollInterval lets the device login tests poll without waiting for the
+// interval requested by the server.
+func WithPollInterval(cmd *LoginCommand, interval time.Duration) *LoginCommand {
+	cmd.pollInterval = interval
+	return cmd
+}
@@ -1,14 +1,19 @@
 package account
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"net/url"
+	"sync/atomic"
 	"time"
 
+	"github.com/vaguevoid/cloud-cli/internal/api"
+	"github.com/vaguevoid/cloud-cli/internal/lib/crypto"
 	"github.com/vaguevoid/cloud-cli/internal/lib/httpx"
 	"github.com/vaguevoid/cloud-cli/internal/lib/system"
 )
@@ -18,14 +23,18 @@ import (
 //=================================================================================================
 
 type LoginCommand struct {
-	Server  string
-	Runtime system.Runtime
-	Keyring system.Keyring
-	Timeout time.Duration
+	Server       string
+	Runtime      system.Runtime
+	Keyring      system.Keyring
+	Timeout      time.Duration
+	Device       bool                   // use the device authorization flow instead of a browser callback
+	OnDeviceCode func(code *DeviceCode) // tells the user where to enter the code
+
+	pollInterval time.Duration // overrides the device poll interval (and slow down) requested by the server, for tests
 }
 
-func Login(cmd *LoginCommand) (*User, error) {
-	return cmd.execute()
+func Login(ctx context.Context, cmd *LoginCommand) (*User, error) {
+	return cmd.execute(ctx)
 }
 
 //=================================================================================================
@@ -33,10 +42,22 @@ func Login(cmd *LoginCommand) (*User, error) {
 //=================================================================================================
 
 type loginServer struct {
-	Port       int
-	JwtChannel chan string
-	ErrChannel chan error
-	Stop       func() error
+	Port            int
+	CallbackChannel chan *loginCallback
+	ErrChannel      chan error
+	Stop            func() error
+}
+
+// loginSession holds the secrets for a single browser login, State must be
+// echoed back by the callback and Verifier proves we started the login when
+// exchanging the code for a JWT.
+type loginSession struct {
+	State    string
+	Verifier string
+}
+
+type loginCallback struct {
+	Code string
 }
 
 type loginTimer struct {
@@ -46,11 +67,11 @@ type loginTimer struct {
 
 //-------------------------------------------------------------------------------------------------
 
-func (cmd *LoginCommand) execute() (*User, error) {
+func (cmd *LoginCommand) execute(ctx context.Context) (*User, error) {
 
 	if cmd.Server == "" {
 		return nil, fmt.Errorf("missing server")
-	} else if cmd.Runtime == nil {
+	} else if cmd.Runtime == nil && !cmd.Device {
 		return nil, fmt.Errorf("missing runtime")
 	} else if cmd.Keyring == nil {
 		return nil, fmt.Errorf("missing keyring")
@@ -62,7 +83,7 @@ func (cmd *LoginCommand) execute() (*User, error) {
 
 	jwt, ok := cmd.Keyring.Get(httpx.ParamJWT)
 	if ok {
-		user, err := cmd.validate(jwt)
+		user, err := validate(ctx, cmd.Server, jwt)
 		if err == nil {
 			return user, nil
 		} else {
@@ -70,23 +91,20 @@ func (cmd *LoginCommand) execute() (*User, error) {
 		}
 	}
 
-	timer := cmd.startTimer()
+	timer := cmd.startTimer(ctx)
 	defer timer.Cancel()
 
-	server, err := cmd.startServer()
-	if err != nil {
-		return nil, err
+	var err error
+	if cmd.Device {
+		jwt, err = cmd.deviceLogin(timer)
+	} else {
+		jwt, err = cmd.browserLogin(timer)
 	}
-	defer server.Stop()
-
-	cmd.launchBrowser(server.Port)
-
-	jwt, err = cmd.waitForLogin(server, timer)
 	if err != nil {
 		return nil, err
 	}
 
-	user, err := cmd.validate(jwt)
+	user, err := validate(ctx, cmd.Server, jwt)
 	if err != nil {
 		return nil, err
 	}
@@ -101,25 +119,60 @@ func (cmd *LoginCommand) execute() (*User, error) {
 
 //-------------------------------------------------------------------------------------------------
 
-func (cmd *LoginCommand) startServer() (*loginServer, error) {
+func (cmd *LoginCommand) browserLogin(timer *loginTimer) (string, error) {
+	session := &loginSession{
+		State:    crypto.RandomToken(32),
+		Verifier: crypto.RandomToken(32),
+	}
+
+	server, err := cmd.startServer(session)
+	if err != nil {
+		return "", err
+	}
+	defer server.Stop()
+
+	cmd.launchBrowser(server.Port, session)
+
+	callback, err := cmd.waitForLogin(server, timer)
+	if err != nil {
+		return "", err
+	}
+
+	return cmd.exchangeCode(timer.Context, session, callback.Code)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func (cmd *LoginCommand) startServer(session *loginSession) (*loginServer, error) {
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
 		return nil, err
 	}
 
-	jwtChannel := make(chan string, 1)
+	callbackChannel := make(chan *loginCallback, 1)
 	errChannel := make(chan error, 1)
+	var completed atomic.Bool
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
 		r.ParseForm()
-		jwt := r.FormValue("jwt")
-		if jwt == "" {
-			http.Error(w, "Missing JWT", http.StatusBadRequest)
-			errChannel <- fmt.Errorf("missing jwt in callback")
+		if !crypto.SecureCompare(r.FormValue(httpx.ParamState), session.State) {
+			http.Error(w, "Invalid login state", http.StatusForbidden) // not ours, keep waiting for the real callback
 			return
 		}
-		jwtChannel <- jwt
+		if !completed.CompareAndSwap(false, true) {
+			http.Error(w, "Login already completed", http.StatusConflict)
+			return
+		}
+		callback := &loginCallback{
+			Code: r.FormValue(httpx.ParamCode),
+		}
+		if callback.Code == "" {
+			http.Error(w, "Missing code", http.StatusBadRequest)
+			errChannel <- fmt.Errorf("missing code in callback")
+			return
+		}
+		callbackChannel <- callback
 		w.Header().Set(httpx.HeaderContentType, httpx.ContentTypeHTML)
 		fmt.Fprintln(w, loginSuccessPage)
 	})
@@ -142,10 +195,10 @@ func (cmd *LoginCommand) startServer() (*loginServer, error) {
 	}
 
 	return &loginServer{
-		Port:       port,
-		JwtChannel: jwtChannel,
-		ErrChannel: errChannel,
-		Stop:       stop,
+		Port:            port,
+		CallbackChannel: callbackChannel,
+		ErrChannel:      errChannel,
+		Stop:            stop,
 	}, nil
 }
 
@@ -176,8 +229,8 @@ const loginSuccessPage string = `
 
 //-------------------------------------------------------------------------------------------------
 
-func (cmd *LoginCommand) startTimer() *loginTimer {
-	ctx, cancel := context.WithTimeout(context.Background(), cmd.Timeout)
+func (cmd *LoginCommand) startTimer(parent context.Context) *loginTimer {
+	ctx, cancel := context.WithTimeout(parent, cmd.Timeout)
 	return &loginTimer{
 		Context: ctx,
 		Cancel:  cancel,
@@ -186,36 +239,70 @@ func (cmd *LoginCommand) startTimer() *loginTimer {
 
 //-------------------------------------------------------------------------------------------------
 
-func (cmd *LoginCommand) launchBrowser(port int) {
+func (cmd *LoginCommand) launchBrowser(port int, session *loginSession) {
 	url, _ := url.Parse(cmd.Server)
 	url.Path = "login"
 	q := url.Query()
 	q.Set(httpx.ParamCLI, "true")
 	q.Set(httpx.ParamOrigin, fmt.Sprintf("http://127.0.0.1:%d/callback", port))
+	q.Set(httpx.ParamState, session.State)
+	q.Set(httpx.ParamCodeChallenge, crypto.PKCEChallenge(session.Verifier))
+	q.Set(httpx.ParamCodeChallengeMethod, crypto.PKCEMethod)
 	url.RawQuery = q.Encode()
 	cmd.Runtime.Open(url.String())
 }
 
 //-------------------------------------------------------------------------------------------------
 
-func (cmd *LoginCommand) waitForLogin(server *loginServer, timer *loginTimer) (string, error) {
+func (cmd *LoginCommand) waitForLogin(server *loginServer, timer *loginTimer) (*loginCallback, error) {
 	select {
-	case jwt := <-server.JwtChannel:
-		return jwt, nil
+	case callback := <-server.CallbackChannel:
+		return callback, nil
 	case err := <-server.ErrChannel:
-		return "", err
+		return nil, err
 	case <-timer.Context.Done():
-		return "", fmt.Errorf("login timed out")
+		if errors.Is(timer.Context.Err(), context.Canceled) {
+			return nil, timer.Context.Err()
+		}
+		return nil, fmt.Errorf("login timed out")
 	}
 }
 
 //-------------------------------------------------------------------------------------------------
 
-func (cmd *LoginCommand) validate(jwt string) (*User, error) {
-	url, _ := url.Parse(cmd.Server)
+func (cmd *LoginCommand) exchangeCode(ctx context.Context, session *loginSession, code string) (string, error) {
+	resp, err := postJSON(ctx, cmd.Server, "api/account/token", map[string]string{
+		"code":         code,
+		"codeVerifier": session.Verifier,
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", api.NewError(resp)
+	}
+
+	var token struct {
+		JWT string `json:"jwt"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	if err != nil {
+		return "", fmt.Errorf("unexpected JSON response: %s", err)
+	} else if token.JWT == "" {
+		return "", fmt.Errorf("missing jwt in token response")
+	}
+	return token.JWT, nil
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func validate(ctx context.Context, server string, jwt string) (*User, error) {
+	url, _ := url.Parse(server)
 	url.Path = "api/account/me"
 
-	req, err := http.NewRequest(http.MethodGet, url.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("unexpected request: %s", err)
 	}
@@ -228,10 +315,8 @@ func (cmd *LoginCommand) validate(jwt string) (*User, error) {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("unauthorized")
-	} else if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	if resp.StatusCode != http.StatusOK {
+		return nil, api.NewError(resp)
 	}
 
 	var user User
@@ -244,3 +329,28 @@ func (cmd *LoginCommand) validate(jwt string) (*User, error) {
 }
 
 //-------------------------------------------------------------------------------------------------
+
+func postJSON(ctx context.Context, server string, path string, body any) (*http.Response, error) {
+	url, _ := url.Parse(server)
+	url.Path = path
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url.String(), bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("unexpected request: %s", err)
+	}
+	req.Header.Set(httpx.HeaderContentType, httpx.ContentTypeJSON)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected response: %s", err)
+	}
+	return resp, nil
+}
+
+//-------------------------------------------------------------------------------------------------
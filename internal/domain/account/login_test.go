@@ -1,6 +1,7 @@
 package account_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/vaguevoid/cloud-cli/internal/domain/account"
+	"github.com/vaguevoid/cloud-cli/internal/lib/crypto"
 	"github.com/vaguevoid/cloud-cli/internal/lib/httpx"
 	"github.com/vaguevoid/cloud-cli/internal/test/assert"
 	"github.com/vaguevoid/cloud-cli/internal/test/mock"
@@ -19,7 +21,7 @@ const TestServer = "https://test.void.dev/"
 //-------------------------------------------------------------------------------------------------
 
 func TestLoginMissingServer(t *testing.T) {
-	user, err := account.Login(&account.LoginCommand{})
+	user, err := account.Login(t.Context(), &account.LoginCommand{})
 	assert.Nil(t, user)
 	assert.NotNil(t, err)
 	assert.Equal(t, "missing server", err.Error())
@@ -28,7 +30,7 @@ func TestLoginMissingServer(t *testing.T) {
 //-------------------------------------------------------------------------------------------------
 
 func TestLoginMissingRuntime(t *testing.T) {
-	user, err := account.Login(&account.LoginCommand{
+	user, err := account.Login(t.Context(), &account.LoginCommand{
 		Server: TestServer,
 	})
 	assert.Nil(t, user)
@@ -39,7 +41,7 @@ func TestLoginMissingRuntime(t *testing.T) {
 //-------------------------------------------------------------------------------------------------
 
 func TestLoginMissingKeyring(t *testing.T) {
-	user, err := account.Login(&account.LoginCommand{
+	user, err := account.Login(t.Context(), &account.LoginCommand{
 		Server:  TestServer,
 		Runtime: mock.Runtime(),
 	})
@@ -52,7 +54,7 @@ func TestLoginMissingKeyring(t *testing.T) {
 
 func TestLoginSuccess(t *testing.T) {
 
-	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	mockServer := httptest.NewServer(withTokenExchange("header.payload.signature", func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "Bearer header.payload.signature", r.Header.Get(httpx.HeaderAuthorization))
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(`{"id":100,"name":"TestLoginSuccess"}`))
@@ -65,7 +67,7 @@ func TestLoginSuccess(t *testing.T) {
 	assert.False(t, keyring.Has(httpx.ParamJWT), "preconditions")
 
 	go func() {
-		user, err := account.Login(&account.LoginCommand{
+		user, err := account.Login(t.Context(), &account.LoginCommand{
 			Server:  mockServer.URL,
 			Runtime: runtime,
 			Keyring: keyring,
@@ -76,13 +78,14 @@ func TestLoginSuccess(t *testing.T) {
 	}()
 	briefPause()
 
-	assert.Regexp(t, `http://127.0.0.1:\d*/login\?cli=true&origin=http%3A%2F%2F127.0.0.1%3A\d*%2Fcallback`, runtime.OpenedURL)
-	opened, err := url.Parse(runtime.OpenedURL)
+	assert.Regexp(t, `http://127.0.0.1:\d*/login\?cli=true&code_challenge=[\w-]{43}&code_challenge_method=S256&origin=http%3A%2F%2F127.0.0.1%3A\d*%2Fcallback&state=[\w-]{43}`, runtime.OpenedURL())
+	opened, err := url.Parse(runtime.OpenedURL())
 	assert.Nil(t, err)
 	origin := opened.Query().Get("origin")
+	state := opened.Query().Get("state")
 
 	client := &http.Client{Timeout: 10 * time.Millisecond}
-	resp, err := client.Get(fmt.Sprintf("%s?%s=header.payload.signature", origin, httpx.ParamJWT))
+	resp, err := client.Get(fmt.Sprintf("%s?%s=auth-code&%s=%s", origin, httpx.ParamCode, httpx.ParamState, state))
 	assert.Nil(t, err)
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 	defer resp.Body.Close()
@@ -112,7 +115,7 @@ func TestLoginJWTAlreadyInKeyring(t *testing.T) {
 	keyring.Set(httpx.ParamJWT, "existing.value")
 
 	go func() {
-		user, err := account.Login(&account.LoginCommand{
+		user, err := account.Login(t.Context(), &account.LoginCommand{
 			Server:  mockServer.URL,
 			Runtime: runtime,
 			Keyring: keyring,
@@ -124,13 +127,13 @@ func TestLoginJWTAlreadyInKeyring(t *testing.T) {
 	user := <-resultChannel
 	assert.Equal(t, 200, user.ID)
 	assert.Equal(t, t.Name(), user.Name)
-	assert.Empty(t, runtime.OpenedURL, "browser was never opened")
+	assert.Empty(t, runtime.OpenedURL(), "browser was never opened")
 }
 
 //-------------------------------------------------------------------------------------------------
 
 func TestLoginInvalidJWTAlreadyInKeyring(t *testing.T) {
-	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	mockServer := httptest.NewServer(withTokenExchange("new.jwt", func(w http.ResponseWriter, r *http.Request) {
 		auth := r.Header.Get(httpx.HeaderAuthorization)
 		if auth == "Bearer old.jwt" {
 			w.WriteHeader(http.StatusUnauthorized)
@@ -148,7 +151,7 @@ func TestLoginInvalidJWTAlreadyInKeyring(t *testing.T) {
 	keyring.Set(httpx.ParamJWT, "old.jwt")
 
 	go func() {
-		user, err := account.Login(&account.LoginCommand{
+		user, err := account.Login(t.Context(), &account.LoginCommand{
 			Server:  mockServer.URL,
 			Runtime: runtime,
 			Keyring: keyring,
@@ -158,13 +161,14 @@ func TestLoginInvalidJWTAlreadyInKeyring(t *testing.T) {
 	}()
 	briefPause()
 
-	assert.Regexp(t, `http://127.0.0.1:\d*/login\?cli=true&origin=http%3A%2F%2F127.0.0.1%3A\d*%2Fcallback`, runtime.OpenedURL)
-	opened, err := url.Parse(runtime.OpenedURL)
+	assert.Regexp(t, `http://127.0.0.1:\d*/login\?cli=true&code_challenge=[\w-]{43}&code_challenge_method=S256&origin=http%3A%2F%2F127.0.0.1%3A\d*%2Fcallback&state=[\w-]{43}`, runtime.OpenedURL())
+	opened, err := url.Parse(runtime.OpenedURL())
 	assert.Nil(t, err)
 	origin := opened.Query().Get("origin")
+	state := opened.Query().Get("state")
 
 	client := &http.Client{Timeout: 10 * time.Millisecond}
-	resp, err := client.Get(fmt.Sprintf("%s?%s=new.jwt", origin, httpx.ParamJWT))
+	resp, err := client.Get(fmt.Sprintf("%s?%s=auth-code&%s=%s", origin, httpx.ParamCode, httpx.ParamState, state))
 	assert.Nil(t, err)
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 	defer resp.Body.Close()
@@ -182,7 +186,7 @@ func TestLoginInvalidJWTAlreadyInKeyring(t *testing.T) {
 
 func TestLoginInvalidJWTReturnedFromServer(t *testing.T) {
 
-	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	mockServer := httptest.NewServer(withTokenExchange("header.payload.signature", func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "Bearer header.payload.signature", r.Header.Get(httpx.HeaderAuthorization))
 		w.WriteHeader(http.StatusUnauthorized)
 	}))
@@ -194,7 +198,7 @@ func TestLoginInvalidJWTReturnedFromServer(t *testing.T) {
 	assert.False(t, keyring.Has(httpx.ParamJWT), "preconditions")
 
 	go func() {
-		user, err := account.Login(&account.LoginCommand{
+		user, err := account.Login(t.Context(), &account.LoginCommand{
 			Server:  mockServer.URL,
 			Runtime: runtime,
 			Keyring: keyring,
@@ -205,13 +209,14 @@ func TestLoginInvalidJWTReturnedFromServer(t *testing.T) {
 	}()
 	briefPause()
 
-	assert.Regexp(t, `http://127.0.0.1:\d*/login\?cli=true&origin=http%3A%2F%2F127.0.0.1%3A\d*%2Fcallback`, runtime.OpenedURL)
-	opened, err := url.Parse(runtime.OpenedURL)
+	assert.Regexp(t, `http://127.0.0.1:\d*/login\?cli=true&code_challenge=[\w-]{43}&code_challenge_method=S256&origin=http%3A%2F%2F127.0.0.1%3A\d*%2Fcallback&state=[\w-]{43}`, runtime.OpenedURL())
+	opened, err := url.Parse(runtime.OpenedURL())
 	assert.Nil(t, err)
 	origin := opened.Query().Get("origin")
+	state := opened.Query().Get("state")
 
 	client := &http.Client{Timeout: 10 * time.Millisecond}
-	resp, err := client.Get(fmt.Sprintf("%s?%s=header.payload.signature", origin, httpx.ParamJWT))
+	resp, err := client.Get(fmt.Sprintf("%s?%s=auth-code&%s=%s", origin, httpx.ParamCode, httpx.ParamState, state))
 	assert.Nil(t, err)
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 	defer resp.Body.Close()
@@ -225,7 +230,7 @@ func TestLoginInvalidJWTReturnedFromServer(t *testing.T) {
 
 func TestLoginInvalidStatusCodeReturnedFromServer(t *testing.T) {
 
-	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	mockServer := httptest.NewServer(withTokenExchange("header.payload.signature", func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "Bearer header.payload.signature", r.Header.Get(httpx.HeaderAuthorization))
 		w.WriteHeader(http.StatusInternalServerError)
 	}))
@@ -237,7 +242,7 @@ func TestLoginInvalidStatusCodeReturnedFromServer(t *testing.T) {
 	assert.False(t, keyring.Has(httpx.ParamJWT), "preconditions")
 
 	go func() {
-		user, err := account.Login(&account.LoginCommand{
+		user, err := account.Login(t.Context(), &account.LoginCommand{
 			Server:  mockServer.URL,
 			Runtime: runtime,
 			Keyring: keyring,
@@ -248,13 +253,14 @@ func TestLoginInvalidStatusCodeReturnedFromServer(t *testing.T) {
 	}()
 	briefPause()
 
-	assert.Regexp(t, `http://127.0.0.1:\d*/login\?cli=true&origin=http%3A%2F%2F127.0.0.1%3A\d*%2Fcallback`, runtime.OpenedURL)
-	opened, err := url.Parse(runtime.OpenedURL)
+	assert.Regexp(t, `http://127.0.0.1:\d*/login\?cli=true&code_challenge=[\w-]{43}&code_challenge_method=S256&origin=http%3A%2F%2F127.0.0.1%3A\d*%2Fcallback&state=[\w-]{43}`, runtime.OpenedURL())
+	opened, err := url.Parse(runtime.OpenedURL())
 	assert.Nil(t, err)
 	origin := opened.Query().Get("origin")
+	state := opened.Query().Get("state")
 
 	client := &http.Client{Timeout: 10 * time.Millisecond}
-	resp, err := client.Get(fmt.Sprintf("%s?%s=header.payload.signature", origin, httpx.ParamJWT))
+	resp, err := client.Get(fmt.Sprintf("%s?%s=auth-code&%s=%s", origin, httpx.ParamCode, httpx.ParamState, state))
 	assert.Nil(t, err)
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 	defer resp.Body.Close()
@@ -268,7 +274,7 @@ func TestLoginInvalidStatusCodeReturnedFromServer(t *testing.T) {
 
 func TestLoginInvalidJSONReturnedFromServer(t *testing.T) {
 
-	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	mockServer := httptest.NewServer(withTokenExchange("header.payload.signature", func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "Bearer header.payload.signature", r.Header.Get(httpx.HeaderAuthorization))
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(`invalid JSON`))
@@ -281,7 +287,7 @@ func TestLoginInvalidJSONReturnedFromServer(t *testing.T) {
 	assert.False(t, keyring.Has(httpx.ParamJWT), "preconditions")
 
 	go func() {
-		user, err := account.Login(&account.LoginCommand{
+		user, err := account.Login(t.Context(), &account.LoginCommand{
 			Server:  mockServer.URL,
 			Runtime: runtime,
 			Keyring: keyring,
@@ -292,13 +298,14 @@ func TestLoginInvalidJSONReturnedFromServer(t *testing.T) {
 	}()
 	briefPause()
 
-	assert.Regexp(t, `http://127.0.0.1:\d*/login\?cli=true&origin=http%3A%2F%2F127.0.0.1%3A\d*%2Fcallback`, runtime.OpenedURL)
-	opened, err := url.Parse(runtime.OpenedURL)
+	assert.Regexp(t, `http://127.0.0.1:\d*/login\?cli=true&code_challenge=[\w-]{43}&code_challenge_method=S256&origin=http%3A%2F%2F127.0.0.1%3A\d*%2Fcallback&state=[\w-]{43}`, runtime.OpenedURL())
+	opened, err := url.Parse(runtime.OpenedURL())
 	assert.Nil(t, err)
 	origin := opened.Query().Get("origin")
+	state := opened.Query().Get("state")
 
 	client := &http.Client{Timeout: 10 * time.Millisecond}
-	resp, err := client.Get(fmt.Sprintf("%s?%s=header.payload.signature", origin, httpx.ParamJWT))
+	resp, err := client.Get(fmt.Sprintf("%s?%s=auth-code&%s=%s", origin, httpx.ParamCode, httpx.ParamState, state))
 	assert.Nil(t, err)
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 	defer resp.Body.Close()
@@ -319,7 +326,7 @@ func TestLoginTimeout(t *testing.T) {
 	assert.False(t, keyring.Has(httpx.ParamJWT), "preconditions")
 
 	go func() {
-		jwt, err := account.Login(&account.LoginCommand{
+		jwt, err := account.Login(t.Context(), &account.LoginCommand{
 			Server:  TestServer,
 			Runtime: runtime,
 			Keyring: keyring,
@@ -331,7 +338,7 @@ func TestLoginTimeout(t *testing.T) {
 	}()
 	briefPause()
 
-	assert.Regexp(t, `https://test.void.dev/login\?cli=true&origin=http%3A%2F%2F127.0.0.1%3A\d*%2Fcallback`, runtime.OpenedURL)
+	assert.Regexp(t, `https://test.void.dev/login\?cli=true&code_challenge=[\w-]{43}&code_challenge_method=S256&origin=http%3A%2F%2F127.0.0.1%3A\d*%2Fcallback&state=[\w-]{43}`, runtime.OpenedURL())
 
 	err := <-errorChannel
 	assert.Equal(t, "login timed out", err.Error())
@@ -349,7 +356,7 @@ func TestLoginMissingJWT(t *testing.T) {
 	assert.False(t, keyring.Has(httpx.ParamJWT), "preconditions")
 
 	go func() {
-		jwt, err := account.Login(&account.LoginCommand{
+		jwt, err := account.Login(t.Context(), &account.LoginCommand{
 			Server:  TestServer,
 			Runtime: runtime,
 			Keyring: keyring,
@@ -360,27 +367,210 @@ func TestLoginMissingJWT(t *testing.T) {
 	}()
 	briefPause()
 
-	assert.Regexp(t, `https://test.void.dev/login\?cli=true&origin=http%3A%2F%2F127.0.0.1%3A\d*%2Fcallback`, runtime.OpenedURL)
-	opened, err := url.Parse(runtime.OpenedURL)
+	assert.Regexp(t, `https://test.void.dev/login\?cli=true&code_challenge=[\w-]{43}&code_challenge_method=S256&origin=http%3A%2F%2F127.0.0.1%3A\d*%2Fcallback&state=[\w-]{43}`, runtime.OpenedURL())
+	opened, err := url.Parse(runtime.OpenedURL())
 	assert.Nil(t, err)
 	origin := opened.Query().Get("origin")
+	state := opened.Query().Get("state")
 
 	client := &http.Client{Timeout: 10 * time.Millisecond}
-	resp, err := client.Get(origin)
+	resp, err := client.Get(fmt.Sprintf("%s?%s=%s", origin, httpx.ParamState, state))
 	assert.Nil(t, err)
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 	defer resp.Body.Close()
 
 	err = <-errorChannel
-	assert.Equal(t, "missing jwt in callback", err.Error())
+	assert.Equal(t, "missing code in callback", err.Error())
 
 	assert.False(t, keyring.Has(httpx.ParamJWT))
 }
 
 //-------------------------------------------------------------------------------------------------
 
+func TestLoginRejectsJWTInCallback(t *testing.T) {
+	runtime := mock.Runtime()
+	keyring := mock.Keyring()
+	errorChannel := make(chan error, 1)
+
+	go func() {
+		user, err := account.Login(t.Context(), &account.LoginCommand{
+			Server:  TestServer,
+			Runtime: runtime,
+			Keyring: keyring,
+		})
+		assert.Nil(t, user)
+		errorChannel <- err
+	}()
+	briefPause()
+
+	opened, err := url.Parse(runtime.OpenedURL())
+	assert.Nil(t, err)
+	origin := opened.Query().Get("origin")
+	state := opened.Query().Get("state")
+
+	client := &http.Client{Timeout: 10 * time.Millisecond}
+	resp, err := client.Get(fmt.Sprintf("%s?%s=header.payload.signature&%s=%s", origin, httpx.ParamJWT, httpx.ParamState, state))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	defer resp.Body.Close()
+
+	err = <-errorChannel
+	assert.Equal(t, "missing code in callback", err.Error())
+	assert.False(t, keyring.Has(httpx.ParamJWT))
+}
+
+//-------------------------------------------------------------------------------------------------
+
+// withTokenExchange answers the code exchange with jwt and passes every other
+// request on to next.
+func withTokenExchange(jwt string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/account/token" {
+			httpx.RespondOk(map[string]string{"jwt": jwt}, w)
+			return
+		}
+		next(w, r)
+	}
+}
+
 func briefPause() {
 	time.Sleep(10 * time.Millisecond) // little bit sketch, but need time for Login() to spin up it's local http server
 }
 
 //-------------------------------------------------------------------------------------------------
+
+func TestLoginCancelled(t *testing.T) {
+	runtime := mock.Runtime()
+	keyring := mock.Keyring()
+	errorChannel := make(chan error, 1)
+	ctx, cancel := context.WithCancel(t.Context())
+
+	go func() {
+		user, err := account.Login(ctx, &account.LoginCommand{
+			Server:  TestServer,
+			Runtime: runtime,
+			Keyring: keyring,
+		})
+		assert.Nil(t, user)
+		errorChannel <- err
+	}()
+	briefPause()
+
+	cancel()
+
+	err := <-errorChannel
+	assert.Equal(t, context.Canceled, err)
+	assert.False(t, keyring.Has(httpx.ParamJWT))
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestLoginExchangesCodeWithPKCE(t *testing.T) {
+	var challenge string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/account/token":
+			assert.RequestMethodEqual(t, http.MethodPost, r)
+			body := assert.RequestJSON[map[string]string](t, r)
+			assert.Equal(t, "auth-code", body["code"])
+			assert.Equal(t, challenge, crypto.PKCEChallenge(body["codeVerifier"]))
+			httpx.RespondOk(map[string]string{"jwt": "header.payload.signature"}, w)
+		case "/api/account/me":
+			assert.Equal(t, "Bearer header.payload.signature", r.Header.Get(httpx.HeaderAuthorization))
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"id":100,"name":"TestLoginExchangesCodeWithPKCE"}`))
+		default:
+			httpx.RespondBadRequest("unexpected "+r.URL.Path, w)
+		}
+	}))
+
+	runtime := mock.Runtime()
+	keyring := mock.Keyring()
+	resultChannel := make(chan *account.User, 1)
+
+	go func() {
+		user, err := account.Login(t.Context(), &account.LoginCommand{
+			Server:  mockServer.URL,
+			Runtime: runtime,
+			Keyring: keyring,
+		})
+		assert.Nil(t, err)
+		resultChannel <- user
+	}()
+	briefPause()
+
+	opened, err := url.Parse(runtime.OpenedURL())
+	assert.Nil(t, err)
+	origin := opened.Query().Get("origin")
+	state := opened.Query().Get("state")
+	challenge = opened.Query().Get(httpx.ParamCodeChallenge)
+
+	client := &http.Client{Timeout: 10 * time.Millisecond}
+	resp, err := client.Get(fmt.Sprintf("%s?%s=auth-code&%s=%s", origin, httpx.ParamCode, httpx.ParamState, state))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	defer resp.Body.Close()
+
+	user := <-resultChannel
+	assert.Equal(t, t.Name(), user.Name)
+
+	savedJwt, ok := keyring.Get(httpx.ParamJWT)
+	assert.True(t, ok)
+	assert.Equal(t, "header.payload.signature", savedJwt)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestLoginRejectsInvalidState(t *testing.T) {
+	mockServer := httptest.NewServer(withTokenExchange("real.jwt.value", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer real.jwt.value", r.Header.Get(httpx.HeaderAuthorization))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id":100,"name":"TestLoginRejectsInvalidState"}`))
+	}))
+
+	runtime := mock.Runtime()
+	keyring := mock.Keyring()
+	resultChannel := make(chan *account.User, 1)
+
+	go func() {
+		user, err := account.Login(t.Context(), &account.LoginCommand{
+			Server:  mockServer.URL,
+			Runtime: runtime,
+			Keyring: keyring,
+		})
+		assert.Nil(t, err)
+		resultChannel <- user
+	}()
+	briefPause()
+
+	opened, err := url.Parse(runtime.OpenedURL())
+	assert.Nil(t, err)
+	origin := opened.Query().Get("origin")
+	state := opened.Query().Get("state")
+
+	client := &http.Client{Timeout: 10 * time.Millisecond}
+
+	resp, err := client.Get(fmt.Sprintf("%s?%s=injected-code", origin, httpx.ParamCode))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = client.Get(fmt.Sprintf("%s?%s=injected-code&%s=wrong", origin, httpx.ParamCode, httpx.ParamState))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = client.Get(fmt.Sprintf("%s?%s=auth-code&%s=%s", origin, httpx.ParamCode, httpx.ParamState, state))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	user := <-resultChannel
+	assert.Equal(t, t.Name(), user.Name)
+
+	savedJwt, ok := keyring.Get(httpx.ParamJWT)
+	assert.True(t, ok)
+	assert.Equal(t, "real.jwt.value", savedJwt)
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,91 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/vaguevoid/cloud-cli/internal/api"
+	"github.com/vaguevoid/cloud-cli/internal/lib/httpx"
+	"github.com/vaguevoid/cloud-cli/internal/lib/system"
+)
+
+//=================================================================================================
+// LOGOUT COMMAND
+//=================================================================================================
+
+type LogoutCommand struct {
+	Server  string
+	Keyring system.Keyring
+	Revoke  bool
+}
+
+// Logout removes the stored JWT for the server, returning false if there was
+// nothing to remove. When Revoke is set the token is also revoked on the
+// server, the local entry is removed even if that fails.
+func Logout(ctx context.Context, cmd *LogoutCommand) (bool, error) {
+	return cmd.execute(ctx)
+}
+
+//=================================================================================================
+// PRIVATE IMPLEMENTATION
+//=================================================================================================
+
+func (cmd *LogoutCommand) execute(ctx context.Context) (bool, error) {
+
+	if cmd.Server == "" {
+		return false, fmt.Errorf("missing server")
+	} else if cmd.Keyring == nil {
+		return false, fmt.Errorf("missing keyring")
+	}
+
+	jwt, ok := cmd.Keyring.Get(httpx.ParamJWT)
+	if !ok {
+		return false, nil
+	}
+
+	var revokeErr error
+	if cmd.Revoke {
+		revokeErr = revoke(ctx, cmd.Server, jwt)
+	}
+
+	err := cmd.Keyring.Del(httpx.ParamJWT)
+	if err != nil {
+		return false, err
+	}
+
+	if revokeErr != nil {
+		return true, fmt.Errorf("logged out locally, but failed to revoke token: %w", revokeErr)
+	}
+	return true, nil
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func revoke(ctx context.Context, server string, jwt string) error {
+	url, _ := url.Parse(server)
+	url.Path = "api/account/logout"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url.String(), nil)
+	if err != nil {
+		return fmt.Errorf("unexpected request: %s", err)
+	}
+	req.Header.Set(httpx.HeaderAuthorization, fmt.Sprintf("Bearer %s", jwt))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unexpected response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusUnauthorized: // unauthorized means already invalid
+		return nil
+	default:
+		return api.NewError(resp)
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
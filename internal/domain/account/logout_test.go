@@ -0,0 +1,98 @@
+package account_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vaguevoid/cloud-cli/internal/domain/account"
+	"github.com/vaguevoid/cloud-cli/internal/lib/httpx"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+	"github.com/vaguevoid/cloud-cli/internal/test/mock"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+func TestLogoutMissingServer(t *testing.T) {
+	_, err := account.Logout(t.Context(), &account.LogoutCommand{})
+	assert.Error(t, "missing server", err)
+}
+
+func TestLogoutMissingKeyring(t *testing.T) {
+	_, err := account.Logout(t.Context(), &account.LogoutCommand{Server: TestServer})
+	assert.Error(t, "missing keyring", err)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestLogout(t *testing.T) {
+	keyring := mock.Keyring()
+	keyring.Set(httpx.ParamJWT, "header.payload.signature")
+
+	ok, err := account.Logout(t.Context(), &account.LogoutCommand{
+		Server:  TestServer,
+		Keyring: keyring,
+	})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, keyring.Has(httpx.ParamJWT))
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestLogoutWhenNotLoggedIn(t *testing.T) {
+	ok, err := account.Logout(t.Context(), &account.LogoutCommand{
+		Server:  TestServer,
+		Keyring: mock.Keyring(),
+	})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestLogoutRevoke(t *testing.T) {
+	revoked := false
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.RequestMethodEqual(t, http.MethodPost, r)
+		assert.RequestPathEqual(t, "/api/account/logout", r)
+		assert.RequestHeaderEqual(t, "Bearer header.payload.signature", httpx.HeaderAuthorization, r)
+		revoked = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	keyring := mock.Keyring()
+	keyring.Set(httpx.ParamJWT, "header.payload.signature")
+
+	ok, err := account.Logout(t.Context(), &account.LogoutCommand{
+		Server:  mockServer.URL,
+		Keyring: keyring,
+		Revoke:  true,
+	})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, revoked)
+	assert.False(t, keyring.Has(httpx.ParamJWT))
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestLogoutRevokeFailed(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httpx.RespondBadRequest("uh oh", w)
+	}))
+
+	keyring := mock.Keyring()
+	keyring.Set(httpx.ParamJWT, "header.payload.signature")
+
+	ok, err := account.Logout(t.Context(), &account.LogoutCommand{
+		Server:  mockServer.URL,
+		Keyring: keyring,
+		Revoke:  true,
+	})
+	assert.Error(t, "logged out locally, but failed to revoke token: unexpected status code 400: uh oh", err)
+	assert.True(t, ok)
+	assert.False(t, keyring.Has(httpx.ParamJWT))
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -1,6 +1,40 @@
 package account
 
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/vaguevoid/cloud-cli/internal/api"
+)
+
 type User struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID            int                `json:"id"`
+	Name          string             `json:"name"`
+	Organizations []api.Organization `json:"organizations,omitempty"`
+}
+
+//-------------------------------------------------------------------------------------------------
+
+// TokenExpiry reads the exp claim from a JWT without verifying its signature,
+// it is only used to tell the user when they will need to login again.
+func TokenExpiry(jwt string) (time.Time, bool) {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if json.Unmarshal(payload, &claims) != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0).UTC(), true
 }
+
+//-------------------------------------------------------------------------------------------------
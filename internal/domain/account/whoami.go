@@ -0,0 +1,67 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vaguevoid/cloud-cli/internal/lib/httpx"
+	"github.com/vaguevoid/cloud-cli/internal/lib/system"
+)
+
+//=================================================================================================
+// WHOAMI COMMAND
+//=================================================================================================
+
+var ErrNotLoggedIn = errors.New("not logged in")
+
+type WhoamiCommand struct {
+	Server  string
+	Keyring system.Keyring
+	Token   string // overrides the keyring when set
+}
+
+type Session struct {
+	User      *User      `json:"user"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+func Whoami(ctx context.Context, cmd *WhoamiCommand) (*Session, error) {
+	return cmd.execute(ctx)
+}
+
+//=================================================================================================
+// PRIVATE IMPLEMENTATION
+//=================================================================================================
+
+func (cmd *WhoamiCommand) execute(ctx context.Context) (*Session, error) {
+
+	if cmd.Server == "" {
+		return nil, fmt.Errorf("missing server")
+	} else if cmd.Keyring == nil && cmd.Token == "" {
+		return nil, fmt.Errorf("missing keyring")
+	}
+
+	jwt := cmd.Token
+	if jwt == "" {
+		var ok bool
+		jwt, ok = cmd.Keyring.Get(httpx.ParamJWT)
+		if !ok {
+			return nil, ErrNotLoggedIn
+		}
+	}
+
+	user, err := validate(ctx, cmd.Server, jwt)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &Session{User: user}
+	if expiry, ok := TokenExpiry(jwt); ok {
+		session.ExpiresAt = &expiry
+	}
+	return session, nil
+}
+
+//-------------------------------------------------------------------------------------------------
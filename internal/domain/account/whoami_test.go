@@ -0,0 +1,114 @@
+package account_test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/vaguevoid/cloud-cli/internal/api"
+	"github.com/vaguevoid/cloud-cli/internal/domain/account"
+	"github.com/vaguevoid/cloud-cli/internal/lib/httpx"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+	"github.com/vaguevoid/cloud-cli/internal/test/mock"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+func makeJWT(payload string) string {
+	return "header." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".signature"
+}
+
+func whoamiServer(t *testing.T, jwt string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.RequestMethodEqual(t, http.MethodGet, r)
+		assert.RequestPathEqual(t, "/api/account/me", r)
+		assert.RequestHeaderEqual(t, "Bearer "+jwt, httpx.HeaderAuthorization, r)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id":100,"name":"Jake","organizations":[{"id":1,"name":"Void","slug":"void"}]}`))
+	}))
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestWhoami(t *testing.T) {
+	jwt := makeJWT(`{"sub":"100","exp":1767225600}`)
+	mockServer := whoamiServer(t, jwt)
+
+	keyring := mock.Keyring()
+	keyring.Set(httpx.ParamJWT, jwt)
+
+	session, err := account.Whoami(t.Context(), &account.WhoamiCommand{
+		Server:  mockServer.URL,
+		Keyring: keyring,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &account.User{
+		ID:   100,
+		Name: "Jake",
+		Organizations: []api.Organization{
+			{ID: 1, Name: "Void", Slug: "void"},
+		},
+	}, session.User)
+	assert.NotNil(t, session.ExpiresAt)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), *session.ExpiresAt)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestWhoamiWithToken(t *testing.T) {
+	mockServer := whoamiServer(t, "personal-access-token")
+
+	session, err := account.Whoami(t.Context(), &account.WhoamiCommand{
+		Server: mockServer.URL,
+		Token:  "personal-access-token",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 100, session.User.ID)
+	assert.Nil(t, session.ExpiresAt)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestWhoamiNotLoggedIn(t *testing.T) {
+	_, err := account.Whoami(t.Context(), &account.WhoamiCommand{
+		Server:  TestServer,
+		Keyring: mock.Keyring(),
+	})
+	assert.Error(t, "not logged in", err)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestWhoamiUnauthorized(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	keyring := mock.Keyring()
+	keyring.Set(httpx.ParamJWT, "header.payload.signature")
+
+	_, err := account.Whoami(t.Context(), &account.WhoamiCommand{
+		Server:  mockServer.URL,
+		Keyring: keyring,
+	})
+	assert.Error(t, "unauthorized", err)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestTokenExpiry(t *testing.T) {
+	expiry, ok := account.TokenExpiry(makeJWT(`{"exp":1767225600}`))
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), expiry)
+
+	_, ok = account.TokenExpiry(makeJWT(`{"sub":"100"}`))
+	assert.False(t, ok)
+	_, ok = account.TokenExpiry("header.payload.signature")
+	assert.False(t, ok)
+	_, ok = account.TokenExpiry("not-a-jwt")
+	assert.False(t, ok)
+}
+
+//-------------------------------------------------------------------------------------------------
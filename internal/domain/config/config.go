@@ -0,0 +1,156 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/BurntSushi/toml"
+	"github.com/vaguevoid/cloud-cli/internal/lib/system"
+)
+
+//=================================================================================================
+// USER CONFIG
+//=================================================================================================
+
+const (
+	DefaultProfile = "default"
+	ConfigFile     = "config.toml"
+)
+
+// Config is the user's ~/.config/void-cloud/config.toml, it holds named
+// profiles and remembers which one is current:
+//
+//	current = "staging"
+//
+//	[profile.staging]
+//	server = "https://staging.void.dev/"
+//	org = "void"
+type Config struct {
+	Current  string              `toml:"current,omitempty"`
+	Profiles map[string]*Profile `toml:"profile,omitempty"`
+
+	path string
+}
+
+type Profile struct {
+	Server string `toml:"server,omitempty" json:"server,omitempty"`
+	Org    string `toml:"org,omitempty" json:"org,omitempty"`
+	Game   string `toml:"game,omitempty" json:"game,omitempty"`
+	Token  string `toml:"token,omitempty" json:"token,omitempty"`
+}
+
+func DefaultPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "void-cloud", ConfigFile)
+}
+
+// Load reads the config at path, a missing file is an empty config.
+func Load(path string) (*Config, error) {
+	config := &Config{
+		Profiles: make(map[string]*Profile),
+		path:     path,
+	}
+	if path == "" {
+		return config, nil
+	}
+	_, err := toml.DecodeFile(path, config)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	if config.Profiles == nil {
+		config.Profiles = make(map[string]*Profile)
+	}
+	return config, nil
+}
+
+func (c *Config) Path() string {
+	return c.path
+}
+
+func (c *Config) Save() error {
+	if c.path == "" {
+		return fmt.Errorf("missing config path")
+	}
+	err := os.MkdirAll(filepath.Dir(c.path), 0700)
+	if err != nil {
+		return err
+	}
+	return system.WriteFileAtomic(c.path, 0600, func(w io.Writer) error { // profiles may hold tokens
+		encoder := toml.NewEncoder(w)
+		encoder.Indent = ""
+		return encoder.Encode(c)
+	})
+}
+
+//-------------------------------------------------------------------------------------------------
+
+// Active returns the name of the profile to use, an explicit name (from
+// --profile) wins over the current profile saved in the config.
+func (c *Config) Active(name string) string {
+	if name != "" {
+		return name
+	} else if c.Current != "" {
+		return c.Current
+	}
+	return DefaultProfile
+}
+
+// Profile returns the named profile, the default profile always exists even
+// if it isn't in the config.
+func (c *Config) Profile(name string) (*Profile, error) {
+	if profile, ok := c.Profiles[name]; ok {
+		return profile, nil
+	} else if name == DefaultProfile {
+		return &Profile{}, nil
+	}
+	return nil, fmt.Errorf("unknown profile %q", name)
+}
+
+func (c *Config) Names() []string {
+	names := make([]string, 0, len(c.Profiles)+1)
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	if !slices.Contains(names, DefaultProfile) {
+		names = append(names, DefaultProfile)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (c *Config) Use(name string) error {
+	if _, err := c.Profile(name); err != nil {
+		return err
+	}
+	c.Current = name
+	if name == DefaultProfile {
+		c.Current = ""
+	}
+	return c.Save()
+}
+
+//-------------------------------------------------------------------------------------------------
+
+// KeyringName namespaces stored credentials by profile, the default profile
+// keeps using the bare server name so existing logins keep working.
+func KeyringName(profile string, server string) string {
+	if profile == "" || profile == DefaultProfile {
+		return server
+	}
+	return fmt.Sprintf("%s@%s", profile, server)
+}
+
+//-------------------------------------------------------------------------------------------------
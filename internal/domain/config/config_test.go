@@ -0,0 +1,118 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vaguevoid/cloud-cli/internal/domain/config"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+	"github.com/vaguevoid/cloud-cli/internal/test/mock"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+const TestConfig = `
+current = "staging"
+
+[profile.staging]
+server = "https://staging.void.dev/"
+org = "void"
+game = "snakes"
+
+[profile.local]
+server = "http://localhost:3000/"
+`
+
+//-------------------------------------------------------------------------------------------------
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+	assert.Equal(t, "/tmp/xdg/void-cloud/config.toml", config.DefaultPath())
+
+	t.Setenv("XDG_CONFIG_HOME", "")
+	home, err := os.UserHomeDir()
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".config", "void-cloud", "config.toml"), config.DefaultPath())
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestLoad(t *testing.T) {
+	tmp := mock.TempDir(t)
+	tmp.AddTextFile(t, "config.toml", TestConfig)
+
+	cfg, err := config.Load(filepath.Join(tmp.Dir, "config.toml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "staging", cfg.Current)
+	assert.Equal(t, []string{"default", "local", "staging"}, cfg.Names())
+	assert.Equal(t, "staging", cfg.Active(""))
+	assert.Equal(t, "local", cfg.Active("local"))
+
+	profile, err := cfg.Profile("staging")
+	assert.NoError(t, err)
+	assert.Equal(t, &config.Profile{Server: "https://staging.void.dev/", Org: "void", Game: "snakes"}, profile)
+
+	profile, err = cfg.Profile("default")
+	assert.NoError(t, err)
+	assert.Equal(t, &config.Profile{}, profile)
+
+	_, err = cfg.Profile("production")
+	assert.Error(t, `unknown profile "production"`, err)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestLoadMissingFile(t *testing.T) {
+	tmp := mock.TempDir(t)
+	cfg, err := config.Load(filepath.Join(tmp.Dir, "config.toml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "default", cfg.Active(""))
+	assert.Equal(t, []string{"default"}, cfg.Names())
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestLoadInvalidFile(t *testing.T) {
+	tmp := mock.TempDir(t)
+	tmp.AddTextFile(t, "config.toml", "current = ")
+	_, err := config.Load(filepath.Join(tmp.Dir, "config.toml"))
+	assert.NotNil(t, err)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestUse(t *testing.T) {
+	tmp := mock.TempDir(t)
+	tmp.AddTextFile(t, "config.toml", TestConfig)
+	path := filepath.Join(tmp.Dir, "config.toml")
+
+	cfg, err := config.Load(path)
+	assert.NoError(t, err)
+	assert.NoError(t, cfg.Use("local"))
+	assert.Error(t, `unknown profile "nope"`, cfg.Use("nope"))
+
+	reloaded, err := config.Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "local", reloaded.Active(""))
+	assert.Equal(t, cfg.Profiles, reloaded.Profiles)
+
+	assert.NoError(t, reloaded.Use("default"))
+	reloaded, err = config.Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "", reloaded.Current)
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestKeyringName(t *testing.T) {
+	assert.Equal(t, "https://play.void.dev/", config.KeyringName("", "https://play.void.dev/"))
+	assert.Equal(t, "https://play.void.dev/", config.KeyringName("default", "https://play.void.dev/"))
+	assert.Equal(t, "staging@https://staging.void.dev/", config.KeyringName("staging", "https://staging.void.dev/"))
+}
+
+//-------------------------------------------------------------------------------------------------
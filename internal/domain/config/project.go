@@ -0,0 +1,210 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/vaguevoid/cloud-cli/internal/lib/system"
+)
+
+//=================================================================================================
+// PROJECT CONFIG
+//=================================================================================================
+
+const (
+	ProjectFile     = "void.toml"
+	ProjectJSONFile = ".void/config.json"
+)
+
+// Project is a void.toml (or .void/config.json) checked in alongside a game
+// so that deploy doesn't need --org and --game every time:
+//
+//	org = "void"
+//	game = "snakes"
+//	path = "dist"
+//	label = "latest"
+//	ignore = ["*.map"]
+//	concurrency = 4
+type Project struct {
+	Org         string   `toml:"org,omitempty" json:"org,omitempty"`
+	Game        string   `toml:"game,omitempty" json:"game,omitempty"`
+	Path        string   `toml:"path,omitempty" json:"path,omitempty"`
+	Label       string   `toml:"label,omitempty" json:"label,omitempty"`
+	Ignore      []string `toml:"ignore,omitempty" json:"ignore,omitempty"`
+	Concurrency int      `toml:"concurrency,omitzero" json:"concurrency,omitempty"`
+
+	file string
+}
+
+// FindProject looks for a project config in dir and each of its parents,
+// returning nil if there isn't one.
+func FindProject(dir string) (*Project, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		for _, name := range []string{ProjectFile, ProjectJSONFile} {
+			file := filepath.Join(dir, filepath.FromSlash(name))
+			if _, err := os.Stat(file); err == nil {
+				return LoadProject(file)
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// NewProject is an empty project that will be saved as void.toml in dir.
+func NewProject(dir string) *Project {
+	return &Project{file: filepath.Join(dir, ProjectFile)}
+}
+
+func LoadProject(file string) (*Project, error) {
+	project := &Project{file: file}
+	if strings.HasSuffix(file, ".json") {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		decoder := json.NewDecoder(f)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(project); err != nil {
+			return nil, fmt.Errorf("invalid project config %s: %w", file, err)
+		}
+		return project, nil
+	}
+
+	meta, err := toml.DecodeFile(file, project)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project config %s: %w", file, err)
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("invalid project config %s: unknown key %q", file, undecoded[0].String())
+	}
+	return project, nil
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func (p *Project) File() string {
+	return p.file
+}
+
+// Dir is the root of the project, for .void/config.json that is the parent
+// of the .void directory.
+func (p *Project) Dir() string {
+	dir := filepath.Dir(p.file)
+	if filepath.Base(dir) == filepath.Dir(filepath.FromSlash(ProjectJSONFile)) {
+		return filepath.Dir(dir)
+	}
+	return dir
+}
+
+func (p *Project) Save() error {
+	if p.file == "" {
+		return fmt.Errorf("missing project config path")
+	}
+	return system.WriteFileAtomic(p.file, 0644, func(w io.Writer) error {
+		if strings.HasSuffix(p.file, ".json") {
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(p)
+		}
+		encoder := toml.NewEncoder(w)
+		encoder.Indent = ""
+		return encoder.Encode(p)
+	})
+}
+
+// BuildPath is Path resolved relative to the project directory.
+func (p *Project) BuildPath() string {
+	if p.Path == "" {
+		return ""
+	} else if filepath.IsAbs(p.Path) {
+		return p.Path
+	}
+	return filepath.Join(p.Dir(), filepath.FromSlash(p.Path))
+}
+
+//=================================================================================================
+// BUILD DIRECTORY DETECTION
+//=================================================================================================
+
+var buildDirs = []string{"dist", "build", "public", "out", "www", "web"}
+
+const maxDetectDepth = 3
+
+// DetectBuildPath guesses which directory under dir holds the built game,
+// one containing an index.html or a .wasm file. Common build directory names
+// are preferred over dir itself (which often has a source index.html), after
+// that the shallowest match wins. The result is relative to dir using forward
+// slashes, or empty when nothing looks like a build.
+func DetectBuildPath(dir string) string {
+	for _, name := range buildDirs {
+		if isBuildDir(filepath.Join(dir, name)) {
+			return name
+		}
+	}
+	if isBuildDir(dir) {
+		return "."
+	}
+
+	level := []string{dir}
+	for depth := 0; depth < maxDetectDepth && len(level) > 0; depth++ {
+		var next []string
+		for _, parent := range level {
+			entries, err := os.ReadDir(parent)
+			if err != nil {
+				continue
+			}
+			for _, entry := range entries {
+				if !entry.IsDir() || skipDir(entry.Name()) {
+					continue
+				}
+				child := filepath.Join(parent, entry.Name())
+				if isBuildDir(child) {
+					rel, err := filepath.Rel(dir, child)
+					if err != nil {
+						return ""
+					}
+					return filepath.ToSlash(rel)
+				}
+				next = append(next, child)
+			}
+		}
+		level = next
+	}
+	return ""
+}
+
+func isBuildDir(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if entry.Name() == "index.html" || filepath.Ext(entry.Name()) == ".wasm" {
+			return true
+		}
+	}
+	return false
+}
+
+func skipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || name == "node_modules" || name == "target" || name == "vendor"
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,121 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vaguevoid/cloud-cli/internal/domain/config"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+	"github.com/vaguevoid/cloud-cli/internal/test/mock"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+const TestProject = `
+org = "void"
+game = "snakes"
+path = "dist"
+label = "latest"
+ignore = ["*.map", "tmp/"]
+concurrency = 4
+`
+
+//-------------------------------------------------------------------------------------------------
+
+func TestFindProject(t *testing.T) {
+	tmp := mock.TempDir(t)
+	tmp.AddTextFile(t, "void.toml", TestProject)
+	tmp.AddTextFile(t, "src/game/main.go", "package main")
+
+	project, err := config.FindProject(filepath.Join(tmp.Dir, "src", "game"))
+	assert.NoError(t, err)
+	assert.NotNil(t, project)
+	assert.Equal(t, "void", project.Org)
+	assert.Equal(t, "snakes", project.Game)
+	assert.Equal(t, "dist", project.Path)
+	assert.Equal(t, "latest", project.Label)
+	assert.Equal(t, []string{"*.map", "tmp/"}, project.Ignore)
+	assert.Equal(t, 4, project.Concurrency)
+	assert.Equal(t, filepath.Join(tmp.Dir, "void.toml"), project.File())
+	assert.Equal(t, tmp.Dir, project.Dir())
+	assert.Equal(t, filepath.Join(tmp.Dir, "dist"), project.BuildPath())
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestFindProjectJSON(t *testing.T) {
+	tmp := mock.TempDir(t)
+	tmp.AddTextFile(t, ".void/config.json", `{"org":"void","game":"snakes","path":"build"}`)
+
+	project, err := config.FindProject(tmp.Dir)
+	assert.NoError(t, err)
+	assert.NotNil(t, project)
+	assert.Equal(t, "void", project.Org)
+	assert.Equal(t, "snakes", project.Game)
+	assert.Equal(t, tmp.Dir, project.Dir())
+	assert.Equal(t, filepath.Join(tmp.Dir, "build"), project.BuildPath())
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestFindProjectNotFound(t *testing.T) {
+	tmp := mock.TempDir(t)
+	project, err := config.FindProject(tmp.Dir)
+	assert.NoError(t, err)
+	assert.Nil(t, project)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestLoadProjectUnknownKey(t *testing.T) {
+	tmp := mock.TempDir(t)
+	tmp.AddTextFile(t, "void.toml", `orgg = "void"`)
+	tmp.AddTextFile(t, "other/.void/config.json", `{"orgg":"void"}`)
+
+	_, err := config.LoadProject(filepath.Join(tmp.Dir, "void.toml"))
+	assert.Error(t, `invalid project config `+filepath.Join(tmp.Dir, "void.toml")+`: unknown key "orgg"`, err)
+
+	_, err = config.LoadProject(filepath.Join(tmp.Dir, "other", ".void", "config.json"))
+	assert.NotNil(t, err)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestSaveProject(t *testing.T) {
+	tmp := mock.TempDir(t)
+	project := config.NewProject(tmp.Dir)
+	project.Org = "void"
+	project.Game = "snakes"
+	project.Path = "dist"
+	assert.NoError(t, project.Save())
+	assert.Equal(t, filepath.Join(tmp.Dir, "void.toml"), project.File())
+
+	content, err := os.ReadFile(project.File())
+	assert.NoError(t, err)
+	assert.Equal(t, "org = \"void\"\ngame = \"snakes\"\npath = \"dist\"\n", string(content))
+
+	loaded, err := config.FindProject(tmp.Dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "snakes", loaded.Game)
+	assert.Equal(t, "dist", loaded.Path)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestDetectBuildPath(t *testing.T) {
+	tmp := mock.TempDir(t)
+	assert.Equal(t, "", config.DetectBuildPath(tmp.Dir))
+
+	tmp.AddTextFile(t, "node_modules/pkg/index.html", "<html>")
+	tmp.AddTextFile(t, "game/web/release/game.wasm", "wasm")
+	assert.Equal(t, "game/web/release", config.DetectBuildPath(tmp.Dir))
+
+	tmp.AddTextFile(t, "index.html", "<html>")
+	assert.Equal(t, ".", config.DetectBuildPath(tmp.Dir))
+
+	tmp.AddTextFile(t, "dist/index.html", "<html>")
+	assert.Equal(t, "dist", config.DetectBuildPath(tmp.Dir))
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,223 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vaguevoid/cloud-cli/internal/api"
+	"github.com/vaguevoid/cloud-cli/internal/domain/config"
+)
+
+//=================================================================================================
+// INIT COMMAND
+//=================================================================================================
+
+const CreateGameOption = "Create a new game"
+
+// Prompter asks the user to fill in whatever the flags left out, when it is
+// nil Init runs non-interactively and fails instead of asking.
+type Prompter interface {
+	Select(label string, options []string, def int) (int, error)
+	Input(label string, value string) (string, error)
+}
+
+type InitCommand struct {
+	API        *api.Client
+	Dir        string
+	Org        string
+	Game       string
+	CreateGame string // name of a new game to create instead of picking one
+	Path       string
+	Label      string
+	Force      bool
+	Prompt     Prompter
+	OnCreated  func(game *api.Game)
+}
+
+type InitResult struct {
+	File        string `json:"file"`
+	Org         string `json:"org"`
+	Game        string `json:"game"`
+	Path        string `json:"path,omitempty"`
+	Label       string `json:"label,omitempty"`
+	CreatedGame bool   `json:"createdGame"`
+}
+
+func Init(ctx context.Context, cmd *InitCommand) (*InitResult, error) {
+	if cmd.API == nil {
+		return nil, fmt.Errorf("missing api client")
+	} else if cmd.Dir == "" {
+		return nil, fmt.Errorf("missing directory")
+	} else if cmd.Game != "" && cmd.CreateGame != "" {
+		return nil, fmt.Errorf("cannot use both game and create game")
+	}
+	return cmd.execute(ctx)
+}
+
+//=================================================================================================
+// PRIVATE IMPLEMENTATION
+//=================================================================================================
+
+func (cmd *InitCommand) execute(ctx context.Context) (*InitResult, error) {
+
+	if err := cmd.checkExisting(); err != nil {
+		return nil, err
+	}
+
+	org, err := cmd.chooseOrg(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	game, created, err := cmd.chooseGame(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := cmd.choosePath()
+	if err != nil {
+		return nil, err
+	}
+
+	project := config.NewProject(cmd.Dir)
+	project.Org = org
+	project.Game = game
+	project.Path = path
+	project.Label = cmd.Label
+	if err := project.Save(); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", project.File(), err)
+	}
+
+	return &InitResult{
+		File:        project.File(),
+		Org:         org,
+		Game:        game,
+		Path:        path,
+		Label:       cmd.Label,
+		CreatedGame: created,
+	}, nil
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func (cmd *InitCommand) checkExisting() error {
+	if cmd.Force {
+		return nil
+	}
+	for _, name := range []string{config.ProjectFile, config.ProjectJSONFile} {
+		file := filepath.Join(cmd.Dir, filepath.FromSlash(name))
+		if _, err := os.Stat(file); err == nil {
+			return fmt.Errorf("%s already exists (use --force to overwrite it)", file)
+		}
+	}
+	return nil
+}
+
+func (cmd *InitCommand) chooseOrg(ctx context.Context) (string, error) {
+	if cmd.Org != "" {
+		return cmd.Org, nil
+	}
+
+	orgs, err := cmd.API.ListOrganizations(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	switch {
+	case len(orgs) == 0:
+		return "", fmt.Errorf("you are not a member of any organization")
+	case len(orgs) == 1:
+		return orgs[0].Slug, nil
+	case cmd.Prompt == nil:
+		slugs := make([]string, len(orgs))
+		for i, org := range orgs {
+			slugs[i] = org.Slug
+		}
+		return "", fmt.Errorf("missing --org, choose one of %s", strings.Join(slugs, ", "))
+	}
+
+	options := make([]string, len(orgs))
+	for i, org := range orgs {
+		options[i] = fmt.Sprintf("%s (%s)", org.Name, org.Slug)
+	}
+	index, err := cmd.Prompt.Select("Organization", options, 0)
+	if err != nil {
+		return "", err
+	}
+	return orgs[index].Slug, nil
+}
+
+func (cmd *InitCommand) chooseGame(ctx context.Context, org string) (string, bool, error) {
+	if cmd.Game != "" {
+		return cmd.Game, false, nil
+	} else if cmd.CreateGame != "" {
+		return cmd.createGame(ctx, org, cmd.CreateGame)
+	}
+
+	games, err := cmd.API.ListGames(ctx, org)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to list games in %s: %w", org, err)
+	}
+
+	if cmd.Prompt == nil {
+		if len(games) == 0 {
+			return "", false, fmt.Errorf("missing --game, %s has no games yet, create one with --create-game", org)
+		}
+		slugs := make([]string, len(games))
+		for i, game := range games {
+			slugs[i] = game.Slug
+		}
+		return "", false, fmt.Errorf("missing --game, choose one of %s (or create one with --create-game)", strings.Join(slugs, ", "))
+	}
+
+	options := make([]string, len(games), len(games)+1)
+	for i, game := range games {
+		options[i] = fmt.Sprintf("%s (%s)", game.Name, game.Slug)
+	}
+	options = append(options, CreateGameOption)
+	index, err := cmd.Prompt.Select("Game", options, 0)
+	if err != nil {
+		return "", false, err
+	} else if index < len(games) {
+		return games[index].Slug, false, nil
+	}
+
+	name, err := cmd.Prompt.Input("Game name", "")
+	if err != nil {
+		return "", false, err
+	} else if name == "" {
+		return "", false, fmt.Errorf("missing game name")
+	}
+	return cmd.createGame(ctx, org, name)
+}
+
+func (cmd *InitCommand) createGame(ctx context.Context, org string, name string) (string, bool, error) {
+	game, err := cmd.API.CreateGame(ctx, org, name)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create game %q: %w", name, err)
+	}
+	if cmd.OnCreated != nil {
+		cmd.OnCreated(game)
+	}
+	return game.Slug, true, nil
+}
+
+func (cmd *InitCommand) choosePath() (string, error) {
+	if cmd.Path != "" {
+		return filepath.ToSlash(cmd.Path), nil
+	}
+	detected := config.DetectBuildPath(cmd.Dir)
+	if cmd.Prompt == nil {
+		return detected, nil
+	}
+	path, err := cmd.Prompt.Input("Build directory", detected)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(path), nil
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,211 @@
+package project_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vaguevoid/cloud-cli/internal/api"
+	"github.com/vaguevoid/cloud-cli/internal/domain/config"
+	"github.com/vaguevoid/cloud-cli/internal/domain/project"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+	"github.com/vaguevoid/cloud-cli/internal/test/mock"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+const (
+	TestToken = "personal-access-token"
+	TestOrgs  = `[{"id":1,"name":"Void","slug":"void"},{"id":2,"name":"Atari","slug":"atari"}]`
+	TestGames = `[{"id":42,"name":"Snakes","slug":"snakes"},{"id":43,"name":"Tetris","slug":"tetris"}]`
+)
+
+type testServer struct {
+	*httptest.Server
+	orgs    string
+	games   string
+	created string
+}
+
+func makeServer(t *testing.T, orgs string, games string) *testServer {
+	s := &testServer{orgs: orgs, games: games}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/account/organizations":
+			w.Write([]byte(s.orgs))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/atari/games":
+			w.Write([]byte(s.games))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/atari/games":
+			body := assert.RequestJSON[map[string]string](t, r)
+			s.created = body["name"]
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":44,"name":"` + s.created + `","slug":"pong"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func makeAPI(t *testing.T, server *testServer) *api.Client {
+	client, err := api.NewClient(server.URL, TestToken)
+	assert.Nil(t, err)
+	return client
+}
+
+func loadProject(t *testing.T, dir string) *config.Project {
+	p, err := config.LoadProject(filepath.Join(dir, config.ProjectFile))
+	assert.NoError(t, err)
+	return p
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestInitMissingApi(t *testing.T) {
+	_, err := project.Init(t.Context(), &project.InitCommand{Dir: t.TempDir()})
+	assert.Error(t, "missing api client", err)
+}
+
+func TestInitGameAndCreateGame(t *testing.T) {
+	server := makeServer(t, TestOrgs, TestGames)
+	_, err := project.Init(t.Context(), &project.InitCommand{
+		API:        makeAPI(t, server),
+		Dir:        t.TempDir(),
+		Game:       "snakes",
+		CreateGame: "Pong",
+	})
+	assert.Error(t, "cannot use both game and create game", err)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestInitInteractive(t *testing.T) {
+	server := makeServer(t, TestOrgs, TestGames)
+	tmp := mock.TempDir(t)
+	tmp.AddTextFile(t, "dist/index.html", "<html>")
+	prompter := mock.Prompter("Atari (atari)", "Tetris (tetris)", "")
+
+	result, err := project.Init(t.Context(), &project.InitCommand{
+		API:    makeAPI(t, server),
+		Dir:    tmp.Dir,
+		Prompt: prompter,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &project.InitResult{
+		File: filepath.Join(tmp.Dir, "void.toml"),
+		Org:  "atari",
+		Game: "tetris",
+		Path: "dist",
+	}, result)
+	assert.Equal(t, []string{"Organization", "Game", "Build directory"}, prompter.Asked)
+
+	p := loadProject(t, tmp.Dir)
+	assert.Equal(t, "atari", p.Org)
+	assert.Equal(t, "tetris", p.Game)
+	assert.Equal(t, "dist", p.Path)
+}
+
+func TestInitInteractiveCreateGame(t *testing.T) {
+	server := makeServer(t, TestOrgs, `[]`)
+	tmp := mock.TempDir(t)
+	var created *api.Game
+
+	result, err := project.Init(t.Context(), &project.InitCommand{
+		API:       makeAPI(t, server),
+		Dir:       tmp.Dir,
+		Prompt:    mock.Prompter("Atari (atari)", project.CreateGameOption, "Pong", "build/web"),
+		OnCreated: func(game *api.Game) { created = game },
+	})
+	assert.NoError(t, err)
+	assert.True(t, result.CreatedGame)
+	assert.Equal(t, "pong", result.Game)
+	assert.Equal(t, "build/web", result.Path)
+	assert.Equal(t, "Pong", server.created)
+	assert.Equal(t, &api.Game{ID: 44, Name: "Pong", Slug: "pong"}, created)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestInitNonInteractive(t *testing.T) {
+	server := makeServer(t, `[{"id":2,"name":"Atari","slug":"atari"}]`, TestGames)
+	tmp := mock.TempDir(t)
+	tmp.AddTextFile(t, "build/game.wasm", "wasm")
+
+	result, err := project.Init(t.Context(), &project.InitCommand{
+		API:   makeAPI(t, server),
+		Dir:   tmp.Dir,
+		Game:  "snakes",
+		Label: "latest",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "atari", result.Org) // the only org is picked automatically
+	assert.Equal(t, "snakes", result.Game)
+	assert.Equal(t, "build", result.Path)
+	assert.Equal(t, "latest", loadProject(t, tmp.Dir).Label)
+}
+
+func TestInitNonInteractiveCreateGame(t *testing.T) {
+	server := makeServer(t, TestOrgs, TestGames)
+	tmp := mock.TempDir(t)
+
+	result, err := project.Init(t.Context(), &project.InitCommand{
+		API:        makeAPI(t, server),
+		Dir:        tmp.Dir,
+		Org:        "atari",
+		CreateGame: "Pong",
+		Path:       "out",
+	})
+	assert.NoError(t, err)
+	assert.True(t, result.CreatedGame)
+	assert.Equal(t, "pong", result.Game)
+	assert.Equal(t, "out", result.Path)
+}
+
+func TestInitNonInteractiveMissingOrg(t *testing.T) {
+	server := makeServer(t, TestOrgs, TestGames)
+	_, err := project.Init(t.Context(), &project.InitCommand{
+		API: makeAPI(t, server),
+		Dir: t.TempDir(),
+	})
+	assert.Error(t, "missing --org, choose one of void, atari", err)
+}
+
+func TestInitNonInteractiveMissingGame(t *testing.T) {
+	server := makeServer(t, TestOrgs, TestGames)
+	_, err := project.Init(t.Context(), &project.InitCommand{
+		API: makeAPI(t, server),
+		Dir: t.TempDir(),
+		Org: "atari",
+	})
+	assert.Error(t, "missing --game, choose one of snakes, tetris (or create one with --create-game)", err)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestInitExistingProject(t *testing.T) {
+	server := makeServer(t, TestOrgs, TestGames)
+	tmp := mock.TempDir(t)
+	tmp.AddTextFile(t, "void.toml", `game = "old"`)
+	cmd := &project.InitCommand{
+		API:  makeAPI(t, server),
+		Dir:  tmp.Dir,
+		Org:  "atari",
+		Game: "snakes",
+	}
+
+	_, err := project.Init(t.Context(), cmd)
+	assert.Error(t, filepath.Join(tmp.Dir, "void.toml")+" already exists (use --force to overwrite it)", err)
+
+	cmd.Force = true
+	_, err = project.Init(t.Context(), cmd)
+	assert.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(tmp.Dir, "void.toml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "org = \"atari\"\ngame = \"snakes\"\n", string(content))
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,211 @@
+package share
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/vaguevoid/cloud-cli/internal/lib/ignore"
+)
+
+//=================================================================================================
+// DEPLOY ARCHIVES
+//=================================================================================================
+
+const StdinPath = "-" // deploy an archive read from stdin
+
+type archiveFormat int
+
+const (
+	archiveZip archiveFormat = iota + 1
+	archiveTarGz
+)
+
+// deployArchive reads the files of a .zip or .tar.gz archive. Entries are
+// always read in archive order so that a .tar.gz can be streamed, the archive
+// is read twice (to hash, then to upload) so stdin is spooled to a temp file.
+type deployArchive struct {
+	name    string // as given, for error messages
+	file    string
+	format  archiveFormat
+	spooled bool
+	zip     *zip.ReadCloser // kept open until close so that entries can be reopened
+}
+
+func openArchive(name string) (*deployArchive, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	magic := make([]byte, 4)
+	n, _ := io.ReadFull(f, magic)
+	magic = magic[:n]
+
+	archive := &deployArchive{name: name, file: name}
+	switch {
+	case bytes.HasPrefix(magic, []byte("PK\x03\x04")), bytes.HasPrefix(magic, []byte("PK\x05\x06")):
+		archive.format = archiveZip
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		archive.format = archiveTarGz
+	default:
+		return nil, fmt.Errorf("%s is not a directory or a .zip or .tar.gz archive", name)
+	}
+	return archive, nil
+}
+
+func spoolArchive(stdin io.Reader) (*deployArchive, error) {
+	if stdin == nil {
+		return nil, fmt.Errorf("missing stdin")
+	}
+	f, err := os.CreateTemp("", "void-deploy-*")
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(f, stdin)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("failed to read archive from stdin: %w", err)
+	}
+
+	archive, err := openArchive(f.Name())
+	if err != nil {
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("stdin is not a .zip or .tar.gz archive")
+	}
+	archive.name = "stdin"
+	archive.spooled = true
+	return archive, nil
+}
+
+func (a *deployArchive) close() {
+	if a.zip != nil {
+		a.zip.Close()
+		a.zip = nil
+	}
+	if a.spooled {
+		os.Remove(a.file)
+	}
+}
+
+// walk calls fn with each regular file in the archive. open reads the
+// content of the entry, a zip entry can be opened again at any time until the
+// archive is closed but a .tar.gz entry can only be read once, before fn
+// returns.
+func (a *deployArchive) walk(fn func(name string, size int64, open func() (io.ReadCloser, error)) error) error {
+	switch a.format {
+	case archiveZip:
+		return a.walkZip(fn)
+	case archiveTarGz:
+		return a.walkTarGz(fn)
+	default:
+		return fmt.Errorf("unsupported archive %s", a.name)
+	}
+}
+
+func (a *deployArchive) walkZip(fn func(name string, size int64, open func() (io.ReadCloser, error)) error) error {
+	if a.zip == nil {
+		r, err := zip.OpenReader(a.file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", a.name, err)
+		}
+		a.zip = r
+	}
+
+	for _, f := range a.zip.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		name, err := a.entryPath(f.Name)
+		if err != nil {
+			return err
+		}
+		if err := fn(name, int64(f.UncompressedSize64), f.Open); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *deployArchive) walkTarGz(fn func(name string, size int64, open func() (io.ReadCloser, error)) error) error {
+	f, err := os.Open(a.file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", a.name, err)
+	}
+	defer gz.Close()
+
+	r := tar.NewReader(gz)
+	for {
+		header, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read %s: %w", a.name, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name, err := a.entryPath(header.Name)
+		if err != nil {
+			return err
+		}
+		if err := fn(name, header.Size, readOnce(io.NopCloser(r))); err != nil {
+			return err
+		}
+	}
+}
+
+// readOnce opens content the first time it is called and fails after that.
+func readOnce(content io.ReadCloser) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		if content == nil {
+			return nil, errors.New("content can only be read once")
+		}
+		r := content
+		content = nil
+		return r, nil
+	}
+}
+
+// entryPath returns the slash separated path of an entry relative to the root
+// of the archive, nothing is written to disk but the server must never see a
+// path outside the deploy.
+func (a *deployArchive) entryPath(name string) (string, error) {
+	cleaned := path.Clean(strings.ReplaceAll(name, `\`, "/"))
+	if path.IsAbs(cleaned) || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("invalid path %s in %s", name, a.name)
+	}
+	return cleaned, nil
+}
+
+//-------------------------------------------------------------------------------------------------
+
+// excluded applies matcher to an archive entry the way filepath.Walk does to
+// a directory, an entry is excluded when it or any of its parents match.
+func excluded(matcher *ignore.Matcher, name string) bool {
+	for i := range len(name) {
+		if name[i] == '/' && matcher.Match(name[:i], true) {
+			return true
+		}
+	}
+	return matcher.Match(name, false)
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,308 @@
+package share_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vaguevoid/cloud-cli/internal/api"
+	"github.com/vaguevoid/cloud-cli/internal/domain/share"
+	"github.com/vaguevoid/cloud-cli/internal/lib/crypto"
+	"github.com/vaguevoid/cloud-cli/internal/lib/httpx"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+type archiveEntry struct {
+	name    string
+	content string
+}
+
+var archiveEntries = []archiveEntry{
+	{"path/", ""}, // directories are skipped
+	{ThirdPath, ThirdContent},
+	{FirstPath, FirstContent},
+	{"./" + SecondPath, SecondContent},
+	{"secrets.env", "SECRET=1"},
+}
+
+var archiveManifest = []share.DeployEntry{
+	{Path: FirstPath, Blake3: crypto.Blake3(FirstContent), ContentLength: len(FirstContent)},
+	{Path: SecondPath, Blake3: crypto.Blake3(SecondContent), ContentLength: len(SecondContent)},
+	{Path: ThirdPath, Blake3: crypto.Blake3(ThirdContent), ContentLength: len(ThirdContent)},
+}
+
+func makeZip(t *testing.T, entries []archiveEntry) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		f, err := w.Create(entry.name)
+		assert.NoError(t, err)
+		_, err = f.Write([]byte(entry.content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func makeTarGz(t *testing.T, entries []archiveEntry) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	w := tar.NewWriter(gz)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: 0644, Size: int64(len(entry.content)), Typeflag: tar.TypeReg}
+		if strings.HasSuffix(entry.name, "/") {
+			header.Typeflag = tar.TypeDir
+			header.Mode = 0755
+		}
+		assert.NoError(t, w.WriteHeader(header))
+		_, err := w.Write([]byte(entry.content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+	assert.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func writeArchive(t *testing.T, name string, content []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, content, 0644))
+	return path
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestArchiveManifest(t *testing.T) {
+	for name, content := range map[string][]byte{
+		"build.zip":    makeZip(t, archiveEntries),
+		"build.tar.gz": makeTarGz(t, archiveEntries),
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, err := share.Deploy(t.Context(), &share.DeployCommand{
+				Path:   writeArchive(t, name, content),
+				DryRun: share.DryRunLocal,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, archiveManifest, result.Manifest)
+		})
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestArchiveIgnore(t *testing.T) {
+	result, err := share.Deploy(t.Context(), &share.DeployCommand{
+		Path:   writeArchive(t, "build.zip", makeZip(t, archiveEntries)),
+		DryRun: share.DryRunLocal,
+		Ignore: []string{"to/", "*.env"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []share.DeployEntry{}, result.Manifest)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestArchiveNotAnArchive(t *testing.T) {
+	path := writeArchive(t, "build.txt", []byte("not an archive"))
+	_, err := share.Deploy(t.Context(), &share.DeployCommand{
+		Path:   path,
+		DryRun: share.DryRunLocal,
+	})
+	assert.Error(t, fmt.Sprintf("%s is not a directory or a .zip or .tar.gz archive", path), err)
+
+	_, err = share.Deploy(t.Context(), &share.DeployCommand{
+		Path:   share.StdinPath,
+		Stdin:  strings.NewReader("not an archive"),
+		DryRun: share.DryRunLocal,
+	})
+	assert.Error(t, "stdin is not a .zip or .tar.gz archive", err)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestArchiveInvalidPath(t *testing.T) {
+	_, err := share.Deploy(t.Context(), &share.DeployCommand{
+		Path:   share.StdinPath,
+		Stdin:  bytes.NewReader(makeTarGz(t, []archiveEntry{{"../escape.txt", "oops"}})),
+		DryRun: share.DryRunLocal,
+	})
+	assert.Error(t, "invalid path ../escape.txt in stdin", err)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestArchiveDeploy(t *testing.T) {
+	for name, content := range map[string][]byte{
+		"zip":    makeZip(t, archiveEntries),
+		"tar.gz": makeTarGz(t, archiveEntries),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var mutex sync.Mutex
+			uploaded := make(map[string]string)
+
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/void/snakes/deploy" {
+					manifest := assert.RequestJSON[[]share.DeployEntry](t, r)
+					assert.Equal(t, archiveManifest, manifest)
+					w.Header().Add(httpx.HeaderXDeployID, fmt.Sprintf("%d", TestDeployID))
+					httpx.RespondAccepted(manifest[1:], w) // assume we already have the first file
+				} else if r.URL.Path == "/api/void/snakes/deploy/42/activate" {
+					httpx.RespondOk(&share.DeployResult{DeployID: TestDeployID, URL: TestDeployURL}, w)
+				} else if path, ok := strings.CutPrefix(r.URL.Path, "/api/void/snakes/deploy/42/upload/"); ok {
+					body, err := io.ReadAll(r.Body)
+					assert.NoError(t, err)
+					mutex.Lock()
+					uploaded[path] = string(body)
+					mutex.Unlock()
+					httpx.RespondOk("ok", w)
+				} else {
+					httpx.RespondBadRequest(fmt.Sprintf("unexpected %s", r.URL.Path), w)
+				}
+			}))
+			defer mockServer.Close()
+
+			api, err := api.NewClient(mockServer.URL, TestToken)
+			assert.NoError(t, err)
+
+			result, err := share.Deploy(t.Context(), &share.DeployCommand{
+				API:        api,
+				Org:        TestOrg,
+				Game:       TestGame,
+				Path:       share.StdinPath,
+				Stdin:      bytes.NewReader(content),
+				JournalDir: t.TempDir(),
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, archiveManifest, result.Manifest)
+			assert.Equal(t, map[string]string{
+				SecondPath: SecondContent,
+				ThirdPath:  ThirdContent,
+			}, uploaded)
+		})
+	}
+}
+
+func TestArchiveDeployRetries(t *testing.T) {
+	for name, test := range map[string]struct {
+		content  []byte
+		attempts int
+	}{
+		"zip":    {makeZip(t, archiveEntries), 2},   // the entry is opened again
+		"tar.gz": {makeTarGz(t, archiveEntries), 1}, // the entry was streamed past
+	} {
+		t.Run(name, func(t *testing.T) {
+			var mutex sync.Mutex
+			attempts := 0
+			uploaded := make(map[string]string)
+
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/void/snakes/deploy" {
+					manifest := assert.RequestJSON[[]share.DeployEntry](t, r)
+					w.Header().Add(httpx.HeaderXDeployID, fmt.Sprintf("%d", TestDeployID))
+					httpx.RespondAccepted(manifest, w)
+				} else if r.URL.Path == "/api/void/snakes/deploy/42/activate" {
+					httpx.RespondOk(&share.DeployResult{DeployID: TestDeployID, URL: TestDeployURL}, w)
+				} else if path, ok := strings.CutPrefix(r.URL.Path, "/api/void/snakes/deploy/42/upload/"); ok {
+					mutex.Lock()
+					defer mutex.Unlock()
+					if path == SecondPath {
+						attempts++
+						if attempts == 1 {
+							w.WriteHeader(http.StatusServiceUnavailable)
+							return
+						}
+					}
+					body, err := io.ReadAll(r.Body)
+					assert.NoError(t, err)
+					uploaded[path] = string(body)
+					httpx.RespondOk("ok", w)
+				} else {
+					httpx.RespondBadRequest(fmt.Sprintf("unexpected %s", r.URL.Path), w)
+				}
+			}))
+			defer mockServer.Close()
+
+			client, err := api.NewClient(mockServer.URL, TestToken)
+			assert.NoError(t, err)
+			client.Retry = api.DefaultRetryPolicy(2)
+			client.Retry.BaseDelay = time.Millisecond
+
+			_, err = share.Deploy(t.Context(), &share.DeployCommand{
+				API:        client,
+				Org:        TestOrg,
+				Game:       TestGame,
+				Path:       share.StdinPath,
+				Stdin:      bytes.NewReader(test.content),
+				JournalDir: t.TempDir(),
+			})
+			assert.Equal(t, test.attempts, attempts)
+			assert.Equal(t, FirstContent, uploaded[FirstPath])
+			assert.Equal(t, ThirdContent, uploaded[ThirdPath])
+			if test.attempts > 1 {
+				assert.NoError(t, err)
+				assert.Equal(t, SecondContent, uploaded[SecondPath])
+			} else {
+				assert.Regexp(t, "failed to upload "+SecondPath, err.Error())
+			}
+		})
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestArchiveDeployFromStdinIsNotJournaled(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/void/snakes/deploy" {
+			manifest := assert.RequestJSON[[]share.DeployEntry](t, r)
+			w.Header().Add(httpx.HeaderXDeployID, fmt.Sprintf("%d", TestDeployID))
+			httpx.RespondAccepted(manifest, w)
+		} else {
+			httpx.RespondBadRequest("upload failed", w)
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := api.NewClient(mockServer.URL, TestToken)
+	assert.NoError(t, err)
+
+	journalDir := t.TempDir()
+	_, err = share.Deploy(t.Context(), &share.DeployCommand{
+		API:        client,
+		Org:        TestOrg,
+		Game:       TestGame,
+		Path:       share.StdinPath,
+		Stdin:      bytes.NewReader(makeZip(t, archiveEntries)),
+		JournalDir: journalDir,
+	})
+	assert.NotNil(t, err)
+
+	journals, err := os.ReadDir(journalDir)
+	assert.NoError(t, err)
+	assert.Length(t, 0, journals) // stdin can't be read again, there is nothing to resume
+}
+
+func TestArchiveResumeFromStdin(t *testing.T) {
+	_, err := share.Deploy(t.Context(), &share.DeployCommand{
+		API:        makeAPI(t),
+		Org:        TestOrg,
+		Game:       TestGame,
+		Path:       share.StdinPath,
+		Resume:     true,
+		JournalDir: t.TempDir(),
+	})
+	assert.Error(t, "cannot resume a deploy from stdin", err)
+}
+
+//-------------------------------------------------------------------------------------------------
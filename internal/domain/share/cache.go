@@ -0,0 +1,122 @@
+package share
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/vaguevoid/cloud-cli/internal/lib/crypto"
+	"github.com/vaguevoid/cloud-cli/internal/lib/system"
+)
+
+//=================================================================================================
+// HASH CACHE
+//=================================================================================================
+
+// HashAlgorithm identifies how manifest hashes are computed, bump it whenever
+// that changes so that previously cached hashes are discarded.
+const HashAlgorithm = "blake3/1"
+
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "void-cloud", "hashes")
+}
+
+//=================================================================================================
+// PRIVATE IMPLEMENTATION
+//=================================================================================================
+
+type hashCache struct {
+	Algorithm string                    `json:"algorithm"`
+	Entries   map[string]hashCacheEntry `json:"entries"`
+
+	file string
+	seen map[string]hashCacheEntry
+}
+
+type hashCacheEntry struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mtime"`
+	Inode   uint64 `json:"inode,omitempty"`
+	Blake3  string `json:"blake3"`
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func loadHashCache(dir string, root string) *hashCache {
+	cache := &hashCache{
+		Algorithm: HashAlgorithm,
+		Entries:   make(map[string]hashCacheEntry),
+		seen:      make(map[string]hashCacheEntry),
+	}
+	if dir == "" {
+		return cache // caching disabled
+	}
+
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return cache
+	}
+	cache.file = filepath.Join(dir, crypto.Blake3(abs)[:32]+".json")
+
+	data, err := os.ReadFile(cache.file)
+	if err != nil {
+		return cache
+	}
+
+	var stored hashCache
+	if json.Unmarshal(data, &stored) != nil || stored.Algorithm != HashAlgorithm || stored.Entries == nil {
+		return cache // corrupt or stale cache, start again
+	}
+	cache.Entries = stored.Entries
+	return cache
+}
+
+func (c *hashCache) Lookup(path string, info os.FileInfo) (string, bool) {
+	entry, ok := c.Entries[path]
+	if !ok || entry != c.entryFor(info, entry.Blake3) {
+		return "", false
+	}
+	c.seen[path] = entry
+	return entry.Blake3, true
+}
+
+func (c *hashCache) Store(path string, info os.FileInfo, blake3 string) {
+	c.seen[path] = c.entryFor(info, blake3)
+}
+
+func (c *hashCache) Save() error {
+	if c.file == "" {
+		return nil
+	}
+	data, err := json.Marshal(&hashCache{
+		Algorithm: c.Algorithm,
+		Entries:   c.seen, // only keep entries for files that still exist
+	})
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(c.file), 0700)
+	if err != nil {
+		return err
+	}
+	return system.WriteFileAtomic(c.file, 0600, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+func (c *hashCache) entryFor(info os.FileInfo, blake3 string) hashCacheEntry {
+	return hashCacheEntry{
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+		Inode:   system.FileID(info),
+		Blake3:  blake3,
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
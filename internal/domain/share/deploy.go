@@ -1,19 +1,25 @@
 package share
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/vaguevoid/cloud-cli/internal/api"
 	"github.com/vaguevoid/cloud-cli/internal/lib/crypto"
 	"github.com/vaguevoid/cloud-cli/internal/lib/httpx"
+	"github.com/vaguevoid/cloud-cli/internal/lib/ignore"
 )
 
 //=================================================================================================
@@ -22,37 +28,103 @@ import (
 
 const (
 	UploadConcurrency = 8
+	IgnoreFile        = ".voidignore"
+	AbortTimeout      = 10 * time.Second
 )
 
+type DryRunMode int
+
+const (
+	DryRunOff   DryRunMode = iota
+	DryRunLocal            // build the manifest only, never contact the server
+	DryRunPlan             // ask the server for the incremental manifest, then cancel the deploy
+)
+
+var disallowedPatterns = []string{
+	"*.ssh",
+	"*.git",
+	"*.env",
+	IgnoreFile,
+}
+
 type DeployCommand struct {
-	API       *api.Client
-	Org       string
-	Game      string
-	Label     string
-	Path      string
-	OnStarted func(deployID int64, manifest []DeployEntry, incremental []DeployEntry)
-	OnUpload  func(deployID int64, path string)
+	API             *api.Client
+	Org             string
+	Game            string
+	Label           string
+	Path            string // a directory, a .zip or .tar.gz archive, or StdinPath to read an archive from Stdin
+	Stdin           io.Reader
+	Pin             bool   // exempt the deploy from automatic cleanup
+	Password        string // require a password to view the deploy, empty for a public deploy
+	HashConcurrency int
+	Concurrency     int
+	Ignore          []string
+	Include         []string
+	Resume          bool
+	JournalDir      string
+	CacheDir        string
+	DryRun          DryRunMode
+	AbortOnCancel   bool
+	OnStarted       func(deployID int64, manifest []DeployEntry, incremental []DeployEntry)
+	OnUpload        func(deployID int64, path string)
+	OnProgress      func(deployID int64, path string, sent int64) // called concurrently from upload goroutines
+	OnUploaded      func(deployID int64, path string)
+	OnFailed        func(deployID int64, path string, err error)
+	OnWarning       func(message string)
+	archive         *deployArchive // set while deploying from an archive
 }
 
 type DeployResult struct {
+	DeployID    int64
+	Slug        string
+	URL         string
+	Pinned      bool
+	Protected   bool
+	Manifest    []DeployEntry
+	Incremental []DeployEntry
+}
+
+// InterruptedError is returned when the context is cancelled after the server
+// has started a deploy, it records what was left behind.
+type InterruptedError struct {
 	DeployID int64
-	Slug     string
-	URL      string
-	Manifest []DeployEntry
+	Uploaded []DeployEntry
+	Pending  []DeployEntry
+	Aborted  bool
+	AbortErr error
+	Cause    error
 }
 
-func Deploy(cmd *DeployCommand) (*DeployResult, error) {
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("deploy %d interrupted: %s", e.DeployID, e.Cause)
+}
+
+func (e *InterruptedError) Unwrap() error {
+	return e.Cause
+}
+
+func Deploy(ctx context.Context, cmd *DeployCommand) (*DeployResult, error) {
+	if cmd.Path == "" {
+		return nil, fmt.Errorf("missing path")
+	} else if cmd.Resume && cmd.DryRun != DryRunOff {
+		return nil, fmt.Errorf("cannot resume a dry run")
+	} else if cmd.Resume && cmd.Path == StdinPath {
+		return nil, fmt.Errorf("cannot resume a deploy from stdin")
+	} else if cmd.DryRun == DryRunLocal {
+		return cmd.execute(ctx) // never talks to the server
+	}
+
 	if cmd.API == nil {
 		return nil, fmt.Errorf("missing api client")
 	} else if cmd.Org == "" {
 		return nil, fmt.Errorf("missing organization")
 	} else if cmd.Game == "" {
 		return nil, fmt.Errorf("missing game")
-	} else if cmd.Path == "" {
-		return nil, fmt.Errorf("missing path")
+	} else if cmd.Resume && cmd.JournalDir == "" {
+		return nil, fmt.Errorf("missing journal directory")
 	}
 
-	return cmd.execute()
+	return cmd.execute(ctx)
 }
 
 type DeployEntry struct {
@@ -61,84 +133,248 @@ type DeployEntry struct {
 	ContentLength int    `json:"contentLength"`
 }
 
+func TotalContentLength(entries []DeployEntry) int64 {
+	var total int64
+	for _, entry := range entries {
+		total += int64(entry.ContentLength)
+	}
+	return total
+}
+
 //=================================================================================================
 // PRIVATE IMPLEMENTATION
 //=================================================================================================
 
-func (cmd *DeployCommand) execute() (*DeployResult, error) {
+func (cmd *DeployCommand) execute(ctx context.Context) (*DeployResult, error) {
 
-	info, err := os.Stat(cmd.Path)
+	err := cmd.openArchive()
 	if err != nil {
-		return nil, fmt.Errorf("directory not found %s", cmd.Path)
-	} else if !info.IsDir() {
-		return nil, fmt.Errorf("%s is not a directory", cmd.Path)
+		return nil, err
+	} else if cmd.archive != nil {
+		defer func() {
+			cmd.archive.close()
+			cmd.archive = nil
+		}()
 	}
 
-	fullManifest, err := cmd.buildManifest()
+	fullManifest, err := cmd.buildManifest(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	deployID, incrementalManifest, err := cmd.startDeploy(fullManifest)
+	switch cmd.DryRun {
+	case DryRunLocal:
+		return &DeployResult{
+			Manifest:    fullManifest,
+			Incremental: fullManifest,
+		}, nil
+	case DryRunPlan:
+		return cmd.planDeploy(ctx, fullManifest)
+	}
+
+	journal, err := cmd.prepareDeploy(ctx, fullManifest)
 	if err != nil {
 		return nil, err
 	}
 
+	deployID := journal.DeployID
+	incrementalManifest := journal.Pending()
+
 	if cmd.OnStarted != nil {
 		cmd.OnStarted(deployID, fullManifest, incrementalManifest)
 	}
 
-	err = cmd.incrementalUpload(deployID, incrementalManifest)
-	if err != nil {
+	err = cmd.incrementalUpload(ctx, deployID, incrementalManifest, journal)
+	if err != nil && ctx.Err() != nil {
+		return nil, cmd.interrupted(ctx, journal, incrementalManifest)
+	} else if err != nil {
 		return nil, err
 	}
 
-	result, err := cmd.activateDeploy(deployID)
-	if err != nil {
+	// the pin and password headers went with the original request, which may
+	// have been an unpinned or public deploy, apply them before it goes live
+	if cmd.Pin && cmd.Resume {
+		if _, err := cmd.API.PinDeploy(ctx, cmd.Org, cmd.Game, deployID); err != nil {
+			return nil, fmt.Errorf("failed to pin deploy %d: %w", deployID, err)
+		}
+	}
+	if cmd.Password != "" && cmd.Resume {
+		if err := cmd.API.SetDeployPassword(ctx, cmd.Org, cmd.Game, deployID, cmd.Password); err != nil {
+			return nil, fmt.Errorf("failed to set the password of deploy %d: %w", deployID, err)
+		}
+	}
+
+	result, err := cmd.activateDeploy(ctx, deployID)
+	if err != nil && ctx.Err() != nil {
+		return nil, cmd.interrupted(ctx, journal, incrementalManifest)
+	} else if err != nil {
 		return nil, err
 	}
 
+	cmd.removeJournal(journal)
+
+	result.Pinned = result.Pinned || cmd.Pin
+	result.Protected = result.Protected || cmd.Password != ""
 	result.Manifest = fullManifest
+	result.Incremental = incrementalManifest
 	return result, nil
 }
 
 //-------------------------------------------------------------------------------------------------
 
-func (cmd *DeployCommand) buildManifest() ([]DeployEntry, error) {
-	manifest := make([]DeployEntry, 0)
+// openArchive opens cmd.Path when it is an archive rather than a directory.
+func (cmd *DeployCommand) openArchive() error {
+	if cmd.Path == StdinPath {
+		archive, err := spoolArchive(cmd.Stdin)
+		cmd.archive = archive
+		return err
+	}
 
-	disallowed := func(path string) bool {
-		name := filepath.Base(path)
-		return strings.HasSuffix(name, ".ssh") ||
-			strings.HasSuffix(name, ".git") ||
-			strings.HasSuffix(name, ".env")
+	info, err := os.Stat(cmd.Path)
+	if err != nil {
+		return fmt.Errorf("directory not found %s", cmd.Path)
+	} else if info.IsDir() {
+		return nil
 	}
+	archive, err := openArchive(cmd.Path)
+	cmd.archive = archive
+	return err
+}
 
-	err := filepath.Walk(cmd.Path, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+//-------------------------------------------------------------------------------------------------
+
+func (cmd *DeployCommand) interrupted(ctx context.Context, journal *DeployJournal, incrementalManifest []DeployEntry) error {
+	uploaded, pending := journal.partition(incrementalManifest)
+
+	result := &InterruptedError{
+		DeployID: journal.DeployID,
+		Uploaded: uploaded,
+		Pending:  pending,
+		Cause:    ctx.Err(),
+	}
+
+	if cmd.AbortOnCancel {
+		abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), AbortTimeout)
+		defer cancel()
+		result.AbortErr = cmd.cancelDeploy(abortCtx, journal.DeployID)
+		if result.AbortErr == nil {
+			result.Aborted = true
+			cmd.removeJournal(journal) // nothing left to resume
 		}
-		if info.IsDir() || disallowed(path) {
-			return nil
+	}
+
+	return result
+}
+
+// removeJournal only warns on failure, the deploy itself is done but a stale
+// journal would make a later --resume pick up the wrong deploy.
+func (cmd *DeployCommand) removeJournal(journal *DeployJournal) {
+	if err := journal.Remove(); err != nil && cmd.OnWarning != nil {
+		cmd.OnWarning(fmt.Sprintf("failed to remove the journal of deploy %d, delete %s before using --resume: %s", journal.DeployID, journal.file, err))
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func (cmd *DeployCommand) planDeploy(ctx context.Context, fullManifest []DeployEntry) (*DeployResult, error) {
+	deployID, incrementalManifest, err := cmd.startDeploy(ctx, fullManifest)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cmd.cancelDeploy(ctx, deployID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeployResult{
+		DeployID:    deployID,
+		Manifest:    fullManifest,
+		Incremental: incrementalManifest,
+	}, nil
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func (cmd *DeployCommand) prepareDeploy(ctx context.Context, fullManifest []DeployEntry) (*DeployJournal, error) {
+	if cmd.Resume {
+		journal, err := loadJournal(cmd.JournalDir, cmd)
+		if err != nil {
+			return nil, err
+		} else if !slices.Equal(journal.Manifest, fullManifest) {
+			return nil, fmt.Errorf("files in %s have changed since deploy %d was interrupted", cmd.Path, journal.DeployID)
 		}
-		f, err := os.Open(path)
+		return journal, nil
+	}
+
+	deployID, incrementalManifest, err := cmd.startDeploy(ctx, fullManifest)
+	if err != nil {
+		return nil, err
+	}
+
+	journal, err := newJournal(cmd.JournalDir, cmd, deployID, fullManifest, incrementalManifest)
+	if err != nil {
+		return nil, err
+	}
+
+	err = journal.Save()
+	if err != nil {
+		return nil, err
+	}
+
+	return journal, nil
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func (cmd *DeployCommand) buildManifest(ctx context.Context) ([]DeployEntry, error) {
+	if cmd.archive != nil {
+		return cmd.buildArchiveManifest(ctx)
+	}
+
+	manifest := make([]DeployEntry, 0)
+	infos := make([]os.FileInfo, 0)
+
+	disallowed := ignore.New(disallowedPatterns...)
+	ignored, err := cmd.buildIgnoreMatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	cache := loadHashCache(cmd.CacheDir, cmd.Path)
+
+	err = filepath.Walk(cmd.Path, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		defer f.Close()
-
-		contentLength := info.Size()
 
 		relPath, err := filepath.Rel(cmd.Path, path)
 		if err != nil {
 			return err
 		}
+		if relPath == "." {
+			return nil
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if disallowed.Match(relPath, info.IsDir()) || ignored.Match(relPath, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		hash, _ := cache.Lookup(relPath, info) // empty hash means we still need to compute it
 
 		manifest = append(manifest, DeployEntry{
 			Path:          relPath,
-			Blake3:        crypto.Blake3(f),
-			ContentLength: int(contentLength),
+			Blake3:        hash,
+			ContentLength: int(info.Size()),
 		})
+		infos = append(infos, info)
 
 		return nil
 	})
@@ -147,14 +383,156 @@ func (cmd *DeployCommand) buildManifest() ([]DeployEntry, error) {
 		return nil, err
 	}
 
+	err = cmd.hashManifest(ctx, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, entry := range manifest {
+		cache.Store(entry.Path, infos[i], entry.Blake3)
+	}
+	cache.Save() // best effort, a missing cache only costs time on the next deploy
+
+	slices.SortFunc(manifest, func(a, b DeployEntry) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return manifest, nil
+}
+
+// buildArchiveManifest hashes each entry as it is streamed from the archive,
+// there is nothing to cache the hashes against.
+func (cmd *DeployCommand) buildArchiveManifest(ctx context.Context) ([]DeployEntry, error) {
+	manifest := make([]DeployEntry, 0)
+	seen := make(map[string]bool)
+
+	disallowed := ignore.New(disallowedPatterns...)
+	ignored, err := cmd.buildIgnoreMatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	err = cmd.archive.walk(func(name string, size int64, open func() (io.ReadCloser, error)) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if excluded(disallowed, name) || excluded(ignored, name) {
+			return nil
+		}
+		if seen[name] {
+			return fmt.Errorf("%s appears more than once in %s", name, cmd.archive.name)
+		}
+		seen[name] = true
+
+		content, err := open()
+		if err != nil {
+			return fmt.Errorf("failed to read %s from %s: %w", name, cmd.archive.name, err)
+		}
+		hash, err := crypto.Blake3Reader(content)
+		content.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read %s from %s: %w", name, cmd.archive.name, err)
+		}
+		manifest = append(manifest, DeployEntry{
+			Path:          name,
+			Blake3:        hash,
+			ContentLength: int(size),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(manifest, func(a, b DeployEntry) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
 	return manifest, nil
 }
 
 //-------------------------------------------------------------------------------------------------
 
-func (cmd *DeployCommand) startDeploy(fullManifest []DeployEntry) (int64, []DeployEntry, error) {
+func (cmd *DeployCommand) hashManifest(ctx context.Context, manifest []DeployEntry) error {
+	concurrency := cmd.HashConcurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+
+	semaphore := make(chan struct{}, concurrency)
+	errorChannel := make(chan error, len(manifest))
+	var wg sync.WaitGroup
+
+	for i := range manifest {
+		entry := &manifest[i] // each goroutine owns exactly one entry
+		if entry.Blake3 != "" {
+			continue
+		}
+		if !acquire(ctx, semaphore) {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			f, err := os.Open(filepath.Join(cmd.Path, entry.Path))
+			if err != nil {
+				errorChannel <- err
+				return
+			}
+			defer f.Close()
+			hash, err := crypto.Blake3Reader(f)
+			if err != nil {
+				errorChannel <- fmt.Errorf("failed to hash %s: %w", entry.Path, err)
+				return
+			}
+			entry.Blake3 = hash
+		}()
+	}
+
+	wg.Wait()
+	close(errorChannel)
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err, ok := <-errorChannel; ok {
+		return err
+	}
+
+	return nil
+}
+
+func (cmd *DeployCommand) buildIgnoreMatcher() (*ignore.Matcher, error) {
+	matcher := ignore.New()
+	if cmd.archive == nil { // an archive is streamed, its ignore file could come after the entries it ignores
+		var err error
+		matcher, err = ignore.Load(filepath.Join(cmd.Path, IgnoreFile))
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, pattern := range cmd.Ignore {
+		matcher.Add(pattern)
+	}
+	for _, pattern := range cmd.Include {
+		matcher.Add("!" + pattern)
+	}
+	return matcher, nil
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func (cmd *DeployCommand) startDeploy(ctx context.Context, fullManifest []DeployEntry) (int64, []DeployEntry, error) {
 	route := cmd.API.Route(cmd.Org, cmd.Game, "deploy", cmd.Label)
-	resp, err := cmd.API.PostJSON(route, fullManifest)
+	header := http.Header{}
+	if cmd.Pin {
+		header.Set(httpx.HeaderXDeployPinned, "true")
+	}
+	if cmd.Password != "" {
+		header.Set(httpx.HeaderXDeployPassword, cmd.Password)
+	}
+	resp, err := cmd.API.PostJSONHeaders(ctx, route, fullManifest, header)
 	if err != nil {
 		return 0, nil, err
 	}
@@ -169,21 +547,24 @@ func (cmd *DeployCommand) startDeploy(fullManifest []DeployEntry) (int64, []Depl
 		err = json.NewDecoder(resp.Body).Decode(&incrementalManifest)
 		return deployID, incrementalManifest, err
 	} else {
-		body, _ := io.ReadAll(resp.Body)
-		return 0, nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+		return 0, nil, api.NewError(resp)
 	}
 }
 
 //-------------------------------------------------------------------------------------------------
 
-func (cmd *DeployCommand) incrementalUpload(deployID int64, incrementalManifest []DeployEntry) error {
-	semaphore := make(chan struct{}, UploadConcurrency)
+func (cmd *DeployCommand) incrementalUpload(ctx context.Context, deployID int64, incrementalManifest []DeployEntry, journal *DeployJournal) error {
+	concurrency := cmd.Concurrency
+	if concurrency <= 0 {
+		concurrency = UploadConcurrency
+	}
+	semaphore := make(chan struct{}, concurrency)
 	errorChannel := make(chan error, len(incrementalManifest))
 	var wg sync.WaitGroup
 
-	for _, entry := range incrementalManifest {
-		path := entry.Path // capture loop variable
-		semaphore <- struct{}{}
+	// launch uploads path (holding a semaphore slot), open is only set when
+	// deploying from an archive
+	launch := func(path string, size int64, open func() (io.ReadCloser, error)) {
 		wg.Add(1)
 		if cmd.OnUpload != nil {
 			cmd.OnUpload(deployID, path)
@@ -191,26 +572,44 @@ func (cmd *DeployCommand) incrementalUpload(deployID int64, incrementalManifest
 		go func() {
 			defer wg.Done()
 			defer func() { <-semaphore }()
-			route := cmd.API.Route(cmd.Org, cmd.Game, "deploy", deployID, "upload", path)
-			fullPath := filepath.Join(cmd.Path, path)
-			resp, err := cmd.API.PostFILE(route, fullPath)
+			err := cmd.upload(ctx, deployID, path, size, open, journal)
 			if err != nil {
+				if cmd.OnFailed != nil {
+					cmd.OnFailed(deployID, path, err)
+				}
 				errorChannel <- err
-			} else if resp.StatusCode != http.StatusOK {
-				errorChannel <- fmt.Errorf("failed to upload to %s: status code %d", route, resp.StatusCode)
+			} else if cmd.OnUploaded != nil {
+				cmd.OnUploaded(deployID, path)
 			}
 		}()
 	}
 
+	var errs []error
+	if cmd.archive != nil {
+		if err := cmd.archiveUpload(ctx, semaphore, incrementalManifest, launch); err != nil {
+			errs = append(errs, err)
+		}
+	} else {
+		for _, entry := range incrementalManifest {
+			if !acquire(ctx, semaphore) {
+				break // cancelled, stop launching new uploads
+			}
+			launch(entry.Path, int64(entry.ContentLength), nil)
+		}
+	}
+
 	wg.Wait()
 	close(errorChannel)
 
-	var errors []error
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	for err := range errorChannel {
-		errors = append(errors, err)
+		errs = append(errs, err)
 	}
-	if len(errors) > 0 {
-		return fmt.Errorf("%v", errors)
+	if len(errs) > 0 {
+		return errors.Join(errs...)
 	}
 
 	return nil
@@ -218,13 +617,87 @@ func (cmd *DeployCommand) incrementalUpload(deployID int64, incrementalManifest
 
 //-------------------------------------------------------------------------------------------------
 
-func (cmd *DeployCommand) activateDeploy(deployID int64) (*DeployResult, error) {
+// archiveUpload streams the archive and launches an upload for each pending
+// entry once there is a free upload slot. A zip entry is opened by its upload
+// (and again for a retry), a .tar.gz entry can only be read as the archive
+// streams past it so it is piped to its upload, which is not retried.
+func (cmd *DeployCommand) archiveUpload(ctx context.Context, semaphore chan struct{}, incrementalManifest []DeployEntry, launch func(path string, size int64, open func() (io.ReadCloser, error))) error {
+	pending := make(map[string]bool, len(incrementalManifest))
+	for _, entry := range incrementalManifest {
+		pending[entry.Path] = true
+	}
+
+	err := cmd.archive.walk(func(name string, size int64, open func() (io.ReadCloser, error)) error {
+		if !pending[name] {
+			return nil
+		}
+		if !acquire(ctx, semaphore) {
+			return ctx.Err() // cancelled, stop launching new uploads
+		}
+		delete(pending, name)
+		if cmd.archive.format == archiveZip {
+			launch(name, size, open)
+			return nil
+		}
+
+		content, err := open()
+		if err != nil {
+			<-semaphore
+			return fmt.Errorf("failed to read %s from %s: %w", name, cmd.archive.name, err)
+		}
+		pr, pw := io.Pipe()
+		launch(name, size, readOnce(pr))
+		_, err = io.Copy(pw, content)
+		if errors.Is(err, io.ErrClosedPipe) {
+			return nil // the upload failed (or was cancelled) before reading it all, it reports why
+		} else if err != nil {
+			pw.CloseWithError(err)
+			return fmt.Errorf("failed to read %s from %s: %w", name, cmd.archive.name, err)
+		}
+		return pw.Close()
+	})
+	if err != nil {
+		return err
+	} else if len(pending) > 0 {
+		return fmt.Errorf("%d files to upload are missing from %s", len(pending), cmd.archive.name)
+	}
+	return nil
+}
+
+func (cmd *DeployCommand) upload(ctx context.Context, deployID int64, path string, size int64, open func() (io.ReadCloser, error), journal *DeployJournal) error {
+	var onProgress func(n int64)
+	if cmd.OnProgress != nil {
+		onProgress = func(n int64) {
+			cmd.OnProgress(deployID, path, n)
+		}
+	}
+	route := cmd.API.Route(cmd.Org, cmd.Game, "deploy", deployID, "upload", path)
+	var resp *http.Response
+	var err error
+	if cmd.archive != nil {
+		resp, err = cmd.API.PostStreamProgress(ctx, route, open, size, onProgress)
+	} else {
+		resp, err = cmd.API.PostFILEProgress(ctx, route, filepath.Join(cmd.Path, path), onProgress)
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to upload %s: %w", path, api.NewError(resp))
+	}
+	return journal.MarkUploaded(path)
+}
+
+func (cmd *DeployCommand) activateDeploy(ctx context.Context, deployID int64) (*DeployResult, error) {
 	route := cmd.API.Route(cmd.Org, cmd.Game, "deploy", deployID, "activate")
-	resp, err := cmd.API.Post(route, nil)
+	resp, err := cmd.API.PostContext(ctx, route, nil)
 	if err != nil {
 		return nil, err
-	} else if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to activate %s: status code %d", route, resp.StatusCode)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to activate deploy %d: %w", deployID, api.NewError(resp))
 	}
 
 	var result DeployResult
@@ -236,3 +709,32 @@ func (cmd *DeployCommand) activateDeploy(deployID int64) (*DeployResult, error)
 }
 
 //-------------------------------------------------------------------------------------------------
+
+func (cmd *DeployCommand) cancelDeploy(ctx context.Context, deployID int64) error {
+	route := cmd.API.Route(cmd.Org, cmd.Game, "deploy", deployID, "cancel")
+	resp, err := cmd.API.PostContext(ctx, route, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("failed to cancel deploy %d: %w", deployID, api.NewError(resp))
+	}
+	return nil
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func acquire(ctx context.Context, semaphore chan struct{}) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case semaphore <- struct{}{}:
+		return true
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
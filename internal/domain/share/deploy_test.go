@@ -1,11 +1,18 @@
 package share_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/vaguevoid/cloud-cli/internal/api"
 	"github.com/vaguevoid/cloud-cli/internal/domain/share"
@@ -45,7 +52,7 @@ func makeAPI(t *testing.T) *api.Client {
 //-------------------------------------------------------------------------------------------------
 
 func TestLoginMissingApi(t *testing.T) {
-	_, err := share.Deploy(&share.DeployCommand{
+	_, err := share.Deploy(t.Context(), &share.DeployCommand{
 		Org:  TestOrg,
 		Game: TestGame,
 		Path: TestPath,
@@ -58,7 +65,7 @@ func TestLoginMissingApi(t *testing.T) {
 
 func TestLoginMissingOrg(t *testing.T) {
 	api := makeAPI(t)
-	_, err := share.Deploy(&share.DeployCommand{
+	_, err := share.Deploy(t.Context(), &share.DeployCommand{
 		API:  api,
 		Game: TestGame,
 		Path: TestPath,
@@ -71,7 +78,7 @@ func TestLoginMissingOrg(t *testing.T) {
 
 func TestLoginMissingGame(t *testing.T) {
 	api := makeAPI(t)
-	_, err := share.Deploy(&share.DeployCommand{
+	_, err := share.Deploy(t.Context(), &share.DeployCommand{
 		API:  api,
 		Org:  TestOrg,
 		Path: TestPath,
@@ -84,7 +91,7 @@ func TestLoginMissingGame(t *testing.T) {
 
 func TestLoginMissingPath(t *testing.T) {
 	api := makeAPI(t)
-	_, err := share.Deploy(&share.DeployCommand{
+	_, err := share.Deploy(t.Context(), &share.DeployCommand{
 		API:  api,
 		Org:  TestOrg,
 		Game: TestGame,
@@ -97,7 +104,7 @@ func TestLoginMissingPath(t *testing.T) {
 
 func TestLoginPathNotFound(t *testing.T) {
 	api := makeAPI(t)
-	_, err := share.Deploy(&share.DeployCommand{
+	_, err := share.Deploy(t.Context(), &share.DeployCommand{
 		API:  api,
 		Org:  TestOrg,
 		Game: TestGame,
@@ -164,7 +171,7 @@ func TestFullDeploy(t *testing.T) {
 		uploads = append(uploads, path)
 	}
 
-	result, err := share.Deploy(&share.DeployCommand{
+	result, err := share.Deploy(t.Context(), &share.DeployCommand{
 		API:      api,
 		Org:      TestOrg,
 		Game:     TestGame,
@@ -187,6 +194,73 @@ func TestFullDeploy(t *testing.T) {
 
 //-------------------------------------------------------------------------------------------------
 
+func TestPinnedDeploy(t *testing.T) {
+
+	mockDir := mock.TempDir(t)
+	mockDir.AddTextFile(t, FirstPath, FirstContent)
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/void/snakes/deploy" {
+			assert.RequestHeaderEqual(t, "true", httpx.HeaderXDeployPinned, r)
+			w.Header().Add(httpx.HeaderXDeployID, fmt.Sprintf("%d", TestDeployID))
+			httpx.RespondAccepted([]share.DeployEntry{}, w)
+		} else if r.URL.Path == "/api/void/snakes/deploy/42/activate" {
+			httpx.RespondOk(&share.DeployResult{DeployID: TestDeployID, URL: TestDeployURL}, w)
+		} else {
+			httpx.RespondBadRequest(fmt.Sprintf("unexpected %s", r.URL.Path), w)
+		}
+	}))
+
+	api, err := api.NewClient(mockServer.URL, TestToken)
+	assert.NoError(t, err)
+
+	result, err := share.Deploy(t.Context(), &share.DeployCommand{
+		API:  api,
+		Org:  TestOrg,
+		Game: TestGame,
+		Path: mockDir.Dir,
+		Pin:  true,
+	})
+	assert.NoError(t, err)
+	assert.True(t, result.Pinned)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestProtectedDeploy(t *testing.T) {
+
+	mockDir := mock.TempDir(t)
+	mockDir.AddTextFile(t, FirstPath, FirstContent)
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/void/snakes/deploy" {
+			assert.RequestHeaderEqual(t, "s3cret", httpx.HeaderXDeployPassword, r)
+			w.Header().Add(httpx.HeaderXDeployID, fmt.Sprintf("%d", TestDeployID))
+			httpx.RespondAccepted([]share.DeployEntry{}, w)
+		} else if r.URL.Path == "/api/void/snakes/deploy/42/activate" {
+			httpx.RespondOk(&share.DeployResult{DeployID: TestDeployID, URL: TestDeployURL}, w)
+		} else {
+			httpx.RespondBadRequest(fmt.Sprintf("unexpected %s", r.URL.Path), w)
+		}
+	}))
+
+	api, err := api.NewClient(mockServer.URL, TestToken)
+	assert.NoError(t, err)
+
+	result, err := share.Deploy(t.Context(), &share.DeployCommand{
+		API:      api,
+		Org:      TestOrg,
+		Game:     TestGame,
+		Path:     mockDir.Dir,
+		Password: "s3cret",
+	})
+	assert.NoError(t, err)
+	assert.True(t, result.Protected)
+	assert.False(t, result.Pinned)
+}
+
+//-------------------------------------------------------------------------------------------------
+
 func TestIncrementalDeploy(t *testing.T) {
 
 	mockDir := mock.TempDir(t)
@@ -242,7 +316,7 @@ func TestIncrementalDeploy(t *testing.T) {
 		uploads = append(uploads, path)
 	}
 
-	result, err := share.Deploy(&share.DeployCommand{
+	result, err := share.Deploy(t.Context(), &share.DeployCommand{
 		API:      api,
 		Org:      TestOrg,
 		Game:     TestGame,
@@ -309,7 +383,7 @@ func TestDeployIgnoresDisallowedFiles(t *testing.T) {
 		uploads = append(uploads, path)
 	}
 
-	result, err := share.Deploy(&share.DeployCommand{
+	result, err := share.Deploy(t.Context(), &share.DeployCommand{
 		API:      api,
 		Org:      TestOrg,
 		Game:     TestGame,
@@ -345,7 +419,7 @@ func TestDeployFailed(t *testing.T) {
 
 	tmp := mock.TempDir(t)
 
-	_, err = share.Deploy(&share.DeployCommand{
+	_, err = share.Deploy(t.Context(), &share.DeployCommand{
 		API:  api,
 		Org:  TestOrg,
 		Game: TestGame,
@@ -357,3 +431,783 @@ func TestDeployFailed(t *testing.T) {
 }
 
 //-------------------------------------------------------------------------------------------------
+
+func TestDeployReportsUploadProgress(t *testing.T) {
+
+	mockDir := mock.TempDir(t)
+	mockDir.AddTextFile(t, FirstPath, FirstContent)
+	mockDir.AddTextFile(t, SecondPath, SecondContent)
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/void/snakes/deploy" {
+			manifest := assert.RequestJSON[[]share.DeployEntry](t, r)
+			w.Header().Add(httpx.HeaderXDeployID, fmt.Sprintf("%d", TestDeployID))
+			httpx.RespondAccepted(manifest, w)
+		} else if r.URL.Path == "/api/void/snakes/deploy/42/upload/"+SecondPath {
+			assert.RequestBody(t, r)
+			httpx.RespondBadRequest("uh oh", w)
+		} else if strings.HasPrefix(r.URL.Path, "/api/void/snakes/deploy/42/upload/") {
+			assert.RequestBody(t, r)
+			httpx.RespondOk("ok", w)
+		} else {
+			httpx.RespondBadRequest(fmt.Sprintf("unexpected %s", r.URL.Path), w)
+		}
+	}))
+
+	api, err := api.NewClient(mockServer.URL, TestToken)
+	assert.NoError(t, err)
+
+	var mutex sync.Mutex
+	sent := make(map[string]int64)
+	uploaded := make([]string, 0)
+	failed := make([]string, 0)
+
+	_, err = share.Deploy(t.Context(), &share.DeployCommand{
+		API:  api,
+		Org:  TestOrg,
+		Game: TestGame,
+		Path: mockDir.Dir,
+		OnProgress: func(deployID int64, path string, n int64) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			sent[path] += n
+		},
+		OnUploaded: func(deployID int64, path string) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			uploaded = append(uploaded, path)
+		},
+		OnFailed: func(deployID int64, path string, err error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			failed = append(failed, path)
+			assert.Error(t, "failed to upload path/to/second.txt: unexpected status code 400: uh oh", err)
+		},
+	})
+
+	assert.Error(t, "failed to upload path/to/second.txt: unexpected status code 400: uh oh", err)
+	assert.Equal(t, map[string]int64{
+		FirstPath:  int64(len(FirstContent)),
+		SecondPath: int64(len(SecondContent)),
+	}, sent)
+	assert.Equal(t, []string{FirstPath}, uploaded)
+	assert.Equal(t, []string{SecondPath}, failed)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestDeployUploadConcurrency(t *testing.T) {
+
+	mockDir := mock.TempDir(t)
+	mockDir.AddTextFile(t, FirstPath, FirstContent)
+	mockDir.AddTextFile(t, SecondPath, SecondContent)
+	mockDir.AddTextFile(t, ThirdPath, ThirdContent)
+
+	var mutex sync.Mutex
+	inflight, maxInflight := 0, 0
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/void/snakes/deploy" {
+			manifest := assert.RequestJSON[[]share.DeployEntry](t, r)
+			w.Header().Add(httpx.HeaderXDeployID, fmt.Sprintf("%d", TestDeployID))
+			httpx.RespondAccepted(manifest, w)
+		} else if strings.HasPrefix(r.URL.Path, "/api/void/snakes/deploy/42/upload/") {
+			mutex.Lock()
+			inflight++
+			maxInflight = max(maxInflight, inflight)
+			mutex.Unlock()
+			assert.RequestBody(t, r)
+			time.Sleep(5 * time.Millisecond)
+			mutex.Lock()
+			inflight--
+			mutex.Unlock()
+			httpx.RespondOk("ok", w)
+		} else {
+			httpx.RespondOk(&share.DeployResult{DeployID: TestDeployID}, w)
+		}
+	}))
+
+	api, err := api.NewClient(mockServer.URL, TestToken)
+	assert.NoError(t, err)
+
+	_, err = share.Deploy(t.Context(), &share.DeployCommand{
+		API:         api,
+		Org:         TestOrg,
+		Game:        TestGame,
+		Path:        mockDir.Dir,
+		Concurrency: 1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, maxInflight)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestResumeInterruptedDeploy(t *testing.T) {
+
+	mockDir := mock.TempDir(t)
+	mockDir.AddTextFile(t, FirstPath, FirstContent)
+	mockDir.AddTextFile(t, SecondPath, SecondContent)
+	mockDir.AddTextFile(t, ThirdPath, ThirdContent)
+
+	journalDir := t.TempDir()
+	interrupted := true
+	activated := false
+	var mu sync.Mutex
+	uploaded := make([]string, 0)
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/api/void/snakes/deploy" {
+			assert.False(t, activated, "resume must not start a new deploy")
+			manifest := assert.RequestJSON[[]share.DeployEntry](t, r)
+			w.Header().Add(httpx.HeaderXDeployID, fmt.Sprintf("%d", TestDeployID))
+			httpx.RespondAccepted(manifest, w)
+		} else if r.URL.Path == "/api/void/snakes/deploy/42/activate" {
+			activated = true
+			httpx.RespondOk(&share.DeployResult{
+				DeployID: TestDeployID,
+				Slug:     TestDeploySlug,
+				URL:      TestDeployURL,
+			}, w)
+		} else if r.URL.Path == "/api/void/snakes/deploy/42/upload/"+ThirdPath && interrupted {
+			httpx.Respond(http.StatusBadGateway, "connection lost", w)
+		} else if strings.HasPrefix(r.URL.Path, "/api/void/snakes/deploy/42/upload/") {
+			uploaded = append(uploaded, strings.TrimPrefix(r.URL.Path, "/api/void/snakes/deploy/42/upload/"))
+			httpx.RespondOk("ok", w)
+		} else {
+			httpx.RespondBadRequest(fmt.Sprintf("unexpected %s", r.URL.Path), w)
+		}
+	}))
+
+	api, err := api.NewClient(mockServer.URL, TestToken)
+	assert.NoError(t, err)
+
+	_, err = share.Deploy(t.Context(), &share.DeployCommand{
+		API:        api,
+		Org:        TestOrg,
+		Game:       TestGame,
+		Path:       mockDir.Dir,
+		JournalDir: journalDir,
+	})
+	assert.NotNil(t, err)
+	assert.False(t, activated)
+	entries, _ := os.ReadDir(journalDir)
+	assert.Length(t, 1, entries, "journal survives the failed deploy")
+
+	interrupted = false
+	uploaded = uploaded[:0]
+	resumed := make([]string, 0)
+
+	result, err := share.Deploy(t.Context(), &share.DeployCommand{
+		API:        api,
+		Org:        TestOrg,
+		Game:       TestGame,
+		Path:       mockDir.Dir,
+		Resume:     true,
+		JournalDir: journalDir,
+		OnUpload: func(deployID int64, path string) {
+			resumed = append(resumed, path)
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, activated)
+	assert.Equal(t, TestDeployID, result.DeployID)
+	assert.Equal(t, []string{ThirdPath}, resumed)
+	assert.Equal(t, []string{ThirdPath}, uploaded)
+
+	entries, _ = os.ReadDir(journalDir)
+	assert.Length(t, 0, entries, "journal removed after activation")
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestResumeProtectsBeforeActivating(t *testing.T) {
+
+	mockDir := mock.TempDir(t)
+	mockDir.AddTextFile(t, FirstPath, FirstContent)
+
+	journalDir := t.TempDir()
+	failUpload := true
+	failPassword := true
+	var mu sync.Mutex
+	calls := make([]string, 0)
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/api/void/snakes/deploy":
+			manifest := assert.RequestJSON[[]share.DeployEntry](t, r)
+			w.Header().Add(httpx.HeaderXDeployID, fmt.Sprintf("%d", TestDeployID))
+			httpx.RespondAccepted(manifest, w)
+		case strings.HasPrefix(r.URL.Path, "/api/void/snakes/deploy/42/upload/") && failUpload:
+			httpx.Respond(http.StatusBadGateway, "connection lost", w)
+		case strings.HasPrefix(r.URL.Path, "/api/void/snakes/deploy/42/upload/"):
+			httpx.RespondOk("ok", w)
+		case r.URL.Path == "/api/void/snakes/deploy/42/password" && failPassword:
+			calls = append(calls, "password")
+			httpx.Respond(http.StatusInternalServerError, "oops", w)
+		case r.URL.Path == "/api/void/snakes/deploy/42/password":
+			calls = append(calls, "password")
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/api/void/snakes/deploy/42/pin":
+			calls = append(calls, "pin")
+			httpx.RespondOk(&api.Deploy{ID: TestDeployID, Pinned: true}, w)
+		case r.URL.Path == "/api/void/snakes/deploy/42/activate":
+			calls = append(calls, "activate")
+			httpx.RespondOk(&share.DeployResult{DeployID: TestDeployID}, w)
+		default:
+			httpx.RespondBadRequest(fmt.Sprintf("unexpected %s", r.URL.Path), w)
+		}
+	}))
+
+	api, err := api.NewClient(mockServer.URL, TestToken)
+	assert.NoError(t, err)
+
+	cmd := &share.DeployCommand{
+		API:        api,
+		Org:        TestOrg,
+		Game:       TestGame,
+		Path:       mockDir.Dir,
+		Pin:        true,
+		Password:   "s3cret",
+		JournalDir: journalDir,
+	}
+	_, err = share.Deploy(t.Context(), cmd)
+	assert.NotNil(t, err)
+
+	failUpload = false
+	cmd.Resume = true
+	_, err = share.Deploy(t.Context(), cmd)
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"pin", "password"}, calls, "never activated without its password")
+	entries, _ := os.ReadDir(journalDir)
+	assert.Length(t, 1, entries, "journal kept to resume again")
+
+	failPassword = false
+	calls = calls[:0]
+	result, err := share.Deploy(t.Context(), cmd)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pin", "password", "activate"}, calls)
+	assert.True(t, result.Protected)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestJournalAppendsUploadedPaths(t *testing.T) {
+
+	mockDir := mock.TempDir(t)
+	mockDir.AddTextFile(t, FirstPath, FirstContent)
+	mockDir.AddTextFile(t, SecondPath, SecondContent)
+	mockDir.AddTextFile(t, ThirdPath, ThirdContent)
+
+	journalDir := t.TempDir()
+	interrupted := true
+	var mu sync.Mutex
+	uploaded := make([]string, 0)
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/api/void/snakes/deploy" {
+			manifest := assert.RequestJSON[[]share.DeployEntry](t, r)
+			w.Header().Add(httpx.HeaderXDeployID, fmt.Sprintf("%d", TestDeployID))
+			httpx.RespondAccepted(manifest, w)
+		} else if r.URL.Path == "/api/void/snakes/deploy/42/activate" {
+			httpx.RespondOk(&share.DeployResult{DeployID: TestDeployID}, w)
+		} else if r.URL.Path == "/api/void/snakes/deploy/42/upload/"+ThirdPath && interrupted {
+			httpx.Respond(http.StatusBadGateway, "connection lost", w)
+		} else if path, ok := strings.CutPrefix(r.URL.Path, "/api/void/snakes/deploy/42/upload/"); ok {
+			uploaded = append(uploaded, path)
+			httpx.RespondOk("ok", w)
+		} else {
+			httpx.RespondBadRequest(fmt.Sprintf("unexpected %s", r.URL.Path), w)
+		}
+	}))
+
+	api, err := api.NewClient(mockServer.URL, TestToken)
+	assert.NoError(t, err)
+
+	cmd := &share.DeployCommand{
+		API:         api,
+		Org:         TestOrg,
+		Game:        TestGame,
+		Path:        mockDir.Dir,
+		JournalDir:  journalDir,
+		Concurrency: 1,
+	}
+	_, err = share.Deploy(t.Context(), cmd)
+	assert.NotNil(t, err)
+
+	entries, _ := os.ReadDir(journalDir)
+	assert.Length(t, 1, entries)
+	file := filepath.Join(journalDir, entries[0].Name())
+	data, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	assert.Length(t, 3, lines, "the header then one line per uploaded file")
+	assert.Equal(t, `"`+FirstPath+`"`, lines[1])
+	assert.Equal(t, `"`+SecondPath+`"`, lines[2])
+
+	// a crash part way through appending a line must not mark the file uploaded
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND, 0600)
+	assert.NoError(t, err)
+	_, err = f.WriteString(`"` + ThirdPath)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	interrupted = false
+	uploaded = uploaded[:0]
+	cmd.Resume = true
+	_, err = share.Deploy(t.Context(), cmd)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{ThirdPath}, uploaded)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestResumeWithoutJournal(t *testing.T) {
+	mockDir := mock.TempDir(t)
+	mockDir.AddTextFile(t, FirstPath, FirstContent)
+
+	_, err := share.Deploy(t.Context(), &share.DeployCommand{
+		API:        makeAPI(t),
+		Org:        TestOrg,
+		Game:       TestGame,
+		Path:       mockDir.Dir,
+		Resume:     true,
+		JournalDir: t.TempDir(),
+	})
+	assert.NotNil(t, err)
+	assert.Error(t, fmt.Sprintf("no interrupted deploy to resume for %s", mockDir.Dir), err)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestResumeAfterFilesChanged(t *testing.T) {
+	mockDir := mock.TempDir(t)
+	mockDir.AddTextFile(t, FirstPath, FirstContent)
+
+	journalDir := t.TempDir()
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/void/snakes/deploy" {
+			manifest := assert.RequestJSON[[]share.DeployEntry](t, r)
+			w.Header().Add(httpx.HeaderXDeployID, fmt.Sprintf("%d", TestDeployID))
+			httpx.RespondAccepted(manifest, w)
+		} else {
+			httpx.Respond(http.StatusBadGateway, "connection lost", w)
+		}
+	}))
+
+	api, err := api.NewClient(mockServer.URL, TestToken)
+	assert.NoError(t, err)
+
+	cmd := &share.DeployCommand{
+		API:        api,
+		Org:        TestOrg,
+		Game:       TestGame,
+		Path:       mockDir.Dir,
+		JournalDir: journalDir,
+	}
+	_, err = share.Deploy(t.Context(), cmd)
+	assert.NotNil(t, err)
+
+	mockDir.AddTextFile(t, FirstPath, "modified")
+
+	cmd.Resume = true
+	_, err = share.Deploy(t.Context(), cmd)
+	assert.NotNil(t, err)
+	assert.Error(t, fmt.Sprintf("files in %s have changed since deploy 42 was interrupted", mockDir.Dir), err)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestDeployHonorsIgnoreFile(t *testing.T) {
+
+	mockDir := mock.TempDir(t)
+	mockDir.AddTextFile(t, share.IgnoreFile, "*.map\n.DS_Store\nsrc/\n!keep.map\n")
+	mockDir.AddTextFile(t, ".git/HEAD", "secret")
+	mockDir.AddTextFile(t, ".DS_Store", "junk")
+	mockDir.AddTextFile(t, "game.js.map", "junk")
+	mockDir.AddTextFile(t, "keep.map", "keep")
+	mockDir.AddTextFile(t, "src/main.ts", "junk")
+	mockDir.AddTextFile(t, "notes.txt", "junk")
+	mockDir.AddTextFile(t, "drafts/draft.bak", "junk")
+	mockDir.AddTextFile(t, "drafts/readme.bak", "readme")
+	mockDir.AddTextFile(t, FirstPath, FirstContent)
+
+	expectedManifest := []share.DeployEntry{
+		{
+			Path:          "drafts/readme.bak",
+			Blake3:        crypto.Blake3("readme"),
+			ContentLength: len("readme"),
+		},
+		{
+			Path:          "keep.map",
+			Blake3:        crypto.Blake3("keep"),
+			ContentLength: len("keep"),
+		},
+		{
+			Path:          FirstPath,
+			Blake3:        crypto.Blake3(FirstContent),
+			ContentLength: len(FirstContent),
+		},
+	}
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/void/snakes/deploy" {
+			manifest := assert.RequestJSON[[]share.DeployEntry](t, r)
+			assert.Equal(t, expectedManifest, manifest)
+			w.Header().Add(httpx.HeaderXDeployID, fmt.Sprintf("%d", TestDeployID))
+			httpx.RespondAccepted(manifest, w)
+		} else if r.URL.Path == "/api/void/snakes/deploy/42/activate" {
+			httpx.RespondOk(&share.DeployResult{DeployID: TestDeployID}, w)
+		} else {
+			httpx.RespondOk("ok", w)
+		}
+	}))
+
+	api, err := api.NewClient(mockServer.URL, TestToken)
+	assert.NoError(t, err)
+
+	result, err := share.Deploy(t.Context(), &share.DeployCommand{
+		API:     api,
+		Org:     TestOrg,
+		Game:    TestGame,
+		Path:    mockDir.Dir,
+		Ignore:  []string{"*.txt", "*.bak"},
+		Include: []string{"path/to/*.txt", "readme.bak"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedManifest, result.Manifest)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestDryRunLocal(t *testing.T) {
+
+	mockDir := mock.TempDir(t)
+	mockDir.AddTextFile(t, FirstPath, FirstContent)
+	mockDir.AddTextFile(t, SecondPath, SecondContent)
+
+	expectedManifest := []share.DeployEntry{
+		{
+			Path:          FirstPath,
+			Blake3:        crypto.Blake3(FirstContent),
+			ContentLength: len(FirstContent),
+		},
+		{
+			Path:          SecondPath,
+			Blake3:        crypto.Blake3(SecondContent),
+			ContentLength: len(SecondContent),
+		},
+	}
+
+	result, err := share.Deploy(t.Context(), &share.DeployCommand{
+		Path:   mockDir.Dir,
+		DryRun: share.DryRunLocal,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), result.DeployID)
+	assert.Equal(t, expectedManifest, result.Manifest)
+	assert.Equal(t, int64(len(FirstContent)+len(SecondContent)), share.TotalContentLength(result.Manifest))
+
+	result, err = share.Deploy(t.Context(), &share.DeployCommand{
+		API:    makeAPI(t),
+		Org:    TestOrg,
+		Game:   TestGame,
+		Label:  TestLabel,
+		Path:   mockDir.Dir,
+		DryRun: share.DryRunLocal,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "", result.URL)
+
+	_, err = share.Deploy(t.Context(), &share.DeployCommand{
+		Path:   mockDir.Dir,
+		DryRun: share.DryRunLocal,
+		Resume: true,
+	})
+	assert.Error(t, "cannot resume a dry run", err)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestDryRunPlan(t *testing.T) {
+
+	mockDir := mock.TempDir(t)
+	mockDir.AddTextFile(t, FirstPath, FirstContent)
+	mockDir.AddTextFile(t, SecondPath, SecondContent)
+	mockDir.AddTextFile(t, ThirdPath, ThirdContent)
+
+	cancelled := false
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.RequestMethodEqual(t, http.MethodPost, r)
+		if r.URL.Path == "/api/void/snakes/deploy/latest" {
+			manifest := assert.RequestJSON[[]share.DeployEntry](t, r)
+			w.Header().Add(httpx.HeaderXDeployID, fmt.Sprintf("%d", TestDeployID))
+			httpx.RespondAccepted(manifest[2:], w)
+		} else if r.URL.Path == "/api/void/snakes/deploy/42/cancel" {
+			cancelled = true
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			assert.Fail(t, fmt.Sprintf("unexpected %s", r.URL.Path))
+		}
+	}))
+
+	api, err := api.NewClient(mockServer.URL, TestToken)
+	assert.NoError(t, err)
+
+	result, err := share.Deploy(t.Context(), &share.DeployCommand{
+		API:    api,
+		Org:    TestOrg,
+		Game:   TestGame,
+		Label:  TestLabel,
+		Path:   mockDir.Dir,
+		DryRun: share.DryRunPlan,
+		OnUpload: func(deployID int64, path string) {
+			assert.Fail(t, "dry run must not upload")
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, cancelled)
+	assert.Equal(t, TestDeployID, result.DeployID)
+	assert.Equal(t, "", result.URL)
+	assert.Length(t, 3, result.Manifest)
+	assert.Length(t, 1, result.Incremental)
+	assert.Equal(t, ThirdPath, result.Incremental[0].Path)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestManifestUsesHashCache(t *testing.T) {
+
+	mockDir := mock.TempDir(t)
+	mockDir.AddTextFile(t, FirstPath, FirstContent)
+	cacheDir := t.TempDir()
+
+	buildManifest := func(cacheDir string) []share.DeployEntry {
+		result, err := share.Deploy(t.Context(), &share.DeployCommand{
+			Path:     mockDir.Dir,
+			CacheDir: cacheDir,
+			DryRun:   share.DryRunLocal,
+		})
+		assert.NoError(t, err)
+		return result.Manifest
+	}
+
+	manifest := buildManifest(cacheDir)
+	assert.Equal(t, crypto.Blake3(FirstContent), manifest[0].Blake3)
+
+	// rewrite the file with same size and mtime, a cache hit proves we did not re-hash
+	fullPath := filepath.Join(mockDir.Dir, FirstPath)
+	info, err := os.Stat(fullPath)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(fullPath, []byte("FIRST"), 0644))
+	assert.Nil(t, os.Chtimes(fullPath, info.ModTime(), info.ModTime()))
+	if newInfo, _ := os.Stat(fullPath); newInfo.ModTime() != info.ModTime() {
+		t.Skip("filesystem does not preserve modification time")
+	}
+
+	manifest = buildManifest(cacheDir)
+	assert.Equal(t, crypto.Blake3(FirstContent), manifest[0].Blake3, "cache hit")
+
+	manifest = buildManifest("")
+	assert.Equal(t, crypto.Blake3("FIRST"), manifest[0].Blake3, "cache disabled")
+
+	// a cache written by a different hash algorithm is discarded
+	entries, err := os.ReadDir(cacheDir)
+	assert.Nil(t, err)
+	assert.Length(t, 1, entries)
+	cacheFile := filepath.Join(cacheDir, entries[0].Name())
+	data, err := os.ReadFile(cacheFile)
+	assert.Nil(t, err)
+	data = []byte(strings.Replace(string(data), share.HashAlgorithm, "md5/0", 1))
+	assert.Nil(t, os.WriteFile(cacheFile, data, 0600))
+
+	manifest = buildManifest(cacheDir)
+	assert.Equal(t, crypto.Blake3("FIRST"), manifest[0].Blake3, "cache invalidated")
+
+	// a modified mtime is a cache miss
+	assert.Nil(t, os.WriteFile(fullPath, []byte("first"), 0644))
+	later := info.ModTime().Add(time.Second)
+	assert.Nil(t, os.Chtimes(fullPath, later, later))
+
+	manifest = buildManifest(cacheDir)
+	assert.Equal(t, crypto.Blake3(FirstContent), manifest[0].Blake3, "cache miss")
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestManifestIsDeterministic(t *testing.T) {
+
+	mockDir := mock.TempDir(t)
+	for i := range 50 {
+		mockDir.AddTextFile(t, fmt.Sprintf("assets/%02d/file.txt", i), fmt.Sprintf("content %d", i))
+	}
+	mockDir.AddTextFile(t, "assets.txt", "sibling of a directory")
+	mockDir.AddTextFile(t, "index.html", "<html></html>")
+
+	buildManifest := func(concurrency int) []share.DeployEntry {
+		result, err := share.Deploy(t.Context(), &share.DeployCommand{
+			Path:            mockDir.Dir,
+			HashConcurrency: concurrency,
+			DryRun:          share.DryRunLocal,
+		})
+		assert.NoError(t, err)
+		return result.Manifest
+	}
+
+	sequential := buildManifest(1)
+	assert.Length(t, 52, sequential)
+	assert.True(t, slices.IsSortedFunc(sequential, func(a, b share.DeployEntry) int {
+		return strings.Compare(a.Path, b.Path)
+	}))
+	assert.Equal(t, "assets.txt", sequential[0].Path)
+	assert.Equal(t, crypto.Blake3("content 7"), sequential[8].Blake3)
+
+	for _, concurrency := range []int{0, 4, 16} {
+		assert.Equal(t, sequential, buildManifest(concurrency))
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestDeployCancelled(t *testing.T) {
+
+	mockDir := mock.TempDir(t)
+	mockDir.AddTextFile(t, FirstPath, FirstContent)
+	mockDir.AddTextFile(t, SecondPath, SecondContent)
+	mockDir.AddTextFile(t, ThirdPath, ThirdContent)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	activated := false
+	aborted := false
+	journalDir := t.TempDir()
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/void/snakes/deploy" {
+			manifest := assert.RequestJSON[[]share.DeployEntry](t, r)
+			w.Header().Add(httpx.HeaderXDeployID, fmt.Sprintf("%d", TestDeployID))
+			httpx.RespondAccepted(manifest, w)
+		} else if r.URL.Path == "/api/void/snakes/deploy/42/activate" {
+			activated = true
+			httpx.RespondOk(&share.DeployResult{DeployID: TestDeployID}, w)
+		} else if r.URL.Path == "/api/void/snakes/deploy/42/cancel" {
+			aborted = true
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			assert.RequestBody(t, r)
+			cancel()             // simulate Ctrl-C while the upload is in flight...
+			<-r.Context().Done() // ...and block until the client gives up
+		}
+	}))
+	defer mockServer.Close()
+
+	api, err := api.NewClient(mockServer.URL, TestToken)
+	assert.NoError(t, err)
+
+	_, err = share.Deploy(ctx, &share.DeployCommand{
+		API:           api,
+		Org:           TestOrg,
+		Game:          TestGame,
+		Path:          mockDir.Dir,
+		JournalDir:    journalDir,
+		AbortOnCancel: true,
+	})
+
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.False(t, activated)
+	assert.True(t, aborted)
+
+	var interrupted *share.InterruptedError
+	assert.True(t, errors.As(err, &interrupted))
+	assert.Equal(t, int64(TestDeployID), interrupted.DeployID)
+	assert.True(t, interrupted.Aborted)
+	assert.Nil(t, interrupted.AbortErr)
+	assert.Length(t, 0, interrupted.Uploaded)
+	assert.Length(t, 3, interrupted.Pending)
+	assert.Equal(t, "deploy 42 interrupted: context canceled", err.Error())
+
+	entries, _ := os.ReadDir(journalDir)
+	assert.Length(t, 0, entries, "aborted deploy can not be resumed")
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestDeployCancelledKeepsPendingDeploy(t *testing.T) {
+
+	mockDir := mock.TempDir(t)
+	mockDir.AddTextFile(t, FirstPath, FirstContent)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	journalDir := t.TempDir()
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/void/snakes/deploy" {
+			manifest := assert.RequestJSON[[]share.DeployEntry](t, r)
+			w.Header().Add(httpx.HeaderXDeployID, fmt.Sprintf("%d", TestDeployID))
+			httpx.RespondAccepted(manifest, w)
+		} else if strings.HasPrefix(r.URL.Path, "/api/void/snakes/deploy/42/upload/") {
+			assert.RequestBody(t, r)
+			cancel()
+			<-r.Context().Done()
+		} else {
+			assert.Fail(t, fmt.Sprintf("unexpected %s", r.URL.Path))
+		}
+	}))
+	defer mockServer.Close()
+
+	api, err := api.NewClient(mockServer.URL, TestToken)
+	assert.NoError(t, err)
+
+	_, err = share.Deploy(ctx, &share.DeployCommand{
+		API:        api,
+		Org:        TestOrg,
+		Game:       TestGame,
+		Path:       mockDir.Dir,
+		JournalDir: journalDir,
+	})
+
+	var interrupted *share.InterruptedError
+	assert.True(t, errors.As(err, &interrupted))
+	assert.False(t, interrupted.Aborted)
+
+	entries, _ := os.ReadDir(journalDir)
+	assert.Length(t, 1, entries, "pending deploy can be resumed")
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestDeployUnauthorized(t *testing.T) {
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(httpx.HeaderXRequestID, "req-42")
+		httpx.Respond(http.StatusUnauthorized, map[string]string{"code": "expired", "message": "token expired"}, w)
+	}))
+	client, err := api.NewClient(mockServer.URL, TestToken)
+	assert.NoError(t, err)
+
+	_, err = share.Deploy(t.Context(), &share.DeployCommand{
+		API:  client,
+		Org:  TestOrg,
+		Game: TestGame,
+		Path: mock.TempDir(t).Dir,
+	})
+
+	assert.True(t, api.IsUnauthorized(err))
+	var apiErr *api.Error
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "expired", apiErr.Code)
+	assert.Equal(t, "req-42", apiErr.RequestID)
+	assert.Error(t, "unexpected status code 401: token expired (request req-42)", err)
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,214 @@
+package share
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/vaguevoid/cloud-cli/internal/lib/crypto"
+	"github.com/vaguevoid/cloud-cli/internal/lib/system"
+)
+
+//=================================================================================================
+// DEPLOY JOURNAL
+//=================================================================================================
+
+// A DeployJournal records an in-progress deploy on disk so that an interrupted
+// upload can be resumed without starting over. The file is a JSON header line,
+// written once, followed by a line for each uploaded path, appended as the
+// uploads complete so a large deploy doesn't rewrite its manifest every time.
+type DeployJournal struct {
+	DeployID    int64           `json:"deployID"`
+	Org         string          `json:"org"`
+	Game        string          `json:"game"`
+	Label       string          `json:"label"`
+	Path        string          `json:"path"`
+	Manifest    []DeployEntry   `json:"manifest"`
+	Incremental []DeployEntry   `json:"incremental"`
+	Uploaded    map[string]bool `json:"uploaded"`
+
+	file string
+	log  *os.File // appends uploaded paths, opened on the first one
+	mu   sync.Mutex
+}
+
+func DefaultJournalDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "void-cloud", "deploys")
+}
+
+func (j *DeployJournal) Pending() []DeployEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	pending := make([]DeployEntry, 0, len(j.Incremental))
+	for _, entry := range j.Incremental {
+		if !j.Uploaded[entry.Path] {
+			pending = append(pending, entry)
+		}
+	}
+	return pending
+}
+
+func (j *DeployJournal) MarkUploaded(path string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Uploaded[path] = true
+	if j.file == "" {
+		return nil // journaling disabled
+	}
+	if j.log == nil {
+		log, err := os.OpenFile(j.file, os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return err
+		}
+		j.log = log
+	}
+	line, err := json.Marshal(path)
+	if err != nil {
+		return err
+	}
+	_, err = j.log.Write(append(line, '\n'))
+	return err
+}
+
+func (j *DeployJournal) Save() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.save()
+}
+
+func (j *DeployJournal) Remove() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.closeLog()
+	if j.file == "" {
+		return nil
+	}
+	err := os.Remove(j.file)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+//=================================================================================================
+// PRIVATE IMPLEMENTATION
+//=================================================================================================
+
+func newJournal(dir string, cmd *DeployCommand, deployID int64, manifest []DeployEntry, incremental []DeployEntry) (*DeployJournal, error) {
+	var file string
+	if dir != "" && cmd.Path != StdinPath { // stdin can't be read again to resume
+		f, err := journalFile(dir, cmd)
+		if err != nil {
+			return nil, err
+		}
+		file = f
+	}
+	return &DeployJournal{
+		DeployID:    deployID,
+		Org:         cmd.Org,
+		Game:        cmd.Game,
+		Label:       cmd.Label,
+		Path:        cmd.Path,
+		Manifest:    manifest,
+		Incremental: incremental,
+		Uploaded:    make(map[string]bool),
+		file:        file,
+	}, nil
+}
+
+func loadJournal(dir string, cmd *DeployCommand) (*DeployJournal, error) {
+	file, err := journalFile(dir, cmd)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no interrupted deploy to resume for %s", cmd.Path)
+	} else if err != nil {
+		return nil, err
+	}
+	header, paths, _ := bytes.Cut(data, []byte("\n"))
+	var journal DeployJournal
+	err = json.Unmarshal(header, &journal)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deploy journal %s: %s", file, err)
+	}
+	if journal.Uploaded == nil {
+		journal.Uploaded = make(map[string]bool)
+	}
+	for {
+		line, rest, complete := bytes.Cut(paths, []byte("\n"))
+		if !complete {
+			break // a line cut short by a crash was never confirmed
+		}
+		var path string
+		if json.Unmarshal(line, &path) == nil {
+			journal.Uploaded[path] = true
+		}
+		paths = rest
+	}
+	journal.file = file
+	return &journal, nil
+}
+
+func (j *DeployJournal) partition(entries []DeployEntry) ([]DeployEntry, []DeployEntry) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	uploaded := make([]DeployEntry, 0)
+	pending := make([]DeployEntry, 0)
+	for _, entry := range entries {
+		if j.Uploaded[entry.Path] {
+			uploaded = append(uploaded, entry)
+		} else {
+			pending = append(pending, entry)
+		}
+	}
+	return uploaded, pending
+}
+
+func journalFile(dir string, cmd *DeployCommand) (string, error) {
+	path, err := filepath.Abs(cmd.Path)
+	if err != nil {
+		return "", err
+	}
+	key := crypto.Blake3(fmt.Sprintf("%s\n%s\n%s\n%s", cmd.Org, cmd.Game, cmd.Label, path))
+	return filepath.Join(dir, key[:32]+".json"), nil
+}
+
+func (j *DeployJournal) save() error {
+	if j.file == "" {
+		return nil // journaling disabled
+	}
+	j.closeLog() // the file is replaced, appends must go to the new one
+	data, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	err = os.MkdirAll(filepath.Dir(j.file), 0700)
+	if err != nil {
+		return err
+	}
+	return system.WriteFileAtomic(j.file, 0600, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+func (j *DeployJournal) closeLog() {
+	if j.log != nil {
+		j.log.Close()
+		j.log = nil
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,98 @@
+package share
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vaguevoid/cloud-cli/internal/api"
+)
+
+//=================================================================================================
+// PROMOTE COMMAND
+//=================================================================================================
+
+type PromoteCommand struct {
+	API  *api.Client
+	Org  string
+	Game string
+	From string // a label, or the ID of the deploy to promote
+	To   string // the label to promote it to
+}
+
+type PromoteResult struct {
+	From     *api.Deploy // the deploy that was promoted
+	DeployID int64       // the new deploy served under To
+	Slug     string
+	URL      string
+	Manifest []DeployEntry // identical to the manifest of From
+}
+
+func Promote(ctx context.Context, cmd *PromoteCommand) (*PromoteResult, error) {
+	if cmd.API == nil {
+		return nil, fmt.Errorf("missing api client")
+	} else if cmd.Org == "" {
+		return nil, fmt.Errorf("missing organization")
+	} else if cmd.Game == "" {
+		return nil, fmt.Errorf("missing game")
+	} else if cmd.From == "" {
+		return nil, fmt.Errorf("missing label to promote from")
+	} else if cmd.To == "" {
+		return nil, fmt.Errorf("missing label to promote to")
+	} else if cmd.From == cmd.To {
+		return nil, fmt.Errorf("cannot promote %s to itself", cmd.From)
+	}
+	return cmd.execute(ctx)
+}
+
+//=================================================================================================
+// PRIVATE IMPLEMENTATION
+//=================================================================================================
+
+func (cmd *PromoteCommand) execute(ctx context.Context) (*PromoteResult, error) {
+
+	from, err := ResolveDeploy(ctx, cmd.API, cmd.Org, cmd.Game, cmd.From)
+	if err != nil {
+		return nil, err
+	}
+	if len(from.Manifest) == 0 {
+		return nil, fmt.Errorf("deploy %d has no files to promote", from.ID)
+	}
+
+	manifest := make([]DeployEntry, len(from.Manifest))
+	for i, entry := range from.Manifest {
+		manifest[i] = DeployEntry{
+			Path:          entry.Path,
+			Blake3:        entry.Blake3,
+			ContentLength: int(entry.ContentLength),
+		}
+	}
+
+	// the server already has every file of a previous deploy, so it should
+	// not ask for any of them, if it does we have nothing to upload them from
+	deploy := &DeployCommand{API: cmd.API, Org: cmd.Org, Game: cmd.Game, Label: cmd.To}
+	deployID, incremental, err := deploy.startDeploy(ctx, manifest)
+	if err != nil {
+		return nil, err
+	}
+	if len(incremental) > 0 {
+		abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), AbortTimeout)
+		defer cancel()
+		deploy.cancelDeploy(abortCtx, deployID)
+		return nil, fmt.Errorf("the server no longer has %d files of deploy %d, deploy them again instead of promoting", len(incremental), from.ID)
+	}
+
+	result, err := deploy.activateDeploy(ctx, deployID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PromoteResult{
+		From:     &from.Deploy,
+		DeployID: result.DeployID,
+		Slug:     result.Slug,
+		URL:      result.URL,
+		Manifest: manifest,
+	}, nil
+}
+
+//-------------------------------------------------------------------------------------------------
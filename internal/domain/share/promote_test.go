@@ -0,0 +1,129 @@
+package share_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vaguevoid/cloud-cli/internal/api"
+	"github.com/vaguevoid/cloud-cli/internal/domain/share"
+	"github.com/vaguevoid/cloud-cli/internal/lib/httpx"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+type promoteServer struct {
+	*httptest.Server
+	started   []share.DeployEntry
+	cancelled bool
+}
+
+func makePromoteServer(t *testing.T, missing []share.DeployEntry) *promoteServer {
+	deploys := []api.Deploy{
+		makeDeploy(43, "qa", true, 3),
+		makeDeploy(42, "latest", true, 2),
+	}
+	s := &promoteServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch fmt.Sprintf("%s %s", r.Method, r.URL.Path) {
+		case "GET /api/void/snakes/labels":
+			httpx.RespondOk([]api.Label{{Name: "qa", DeployID: 43}, {Name: "latest", DeployID: 42}}, w)
+		case "GET /api/void/snakes/deploy/43":
+			httpx.RespondOk(&api.DeployDetail{Deploy: deploys[0], Manifest: []api.ManifestEntry{IndexV2, GameV1}}, w)
+		case "POST /api/void/snakes/deploy/latest":
+			s.started = assert.RequestJSON[[]share.DeployEntry](t, r)
+			w.Header().Add(httpx.HeaderXDeployID, "44")
+			httpx.RespondAccepted(missing, w)
+		case "POST /api/void/snakes/deploy/44/activate":
+			httpx.RespondOk(&share.DeployResult{DeployID: 44, Slug: "slug-44", URL: TestDeployURL}, w)
+		case "POST /api/void/snakes/deploy/44/cancel":
+			s.cancelled = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			httpx.RespondBadRequest(fmt.Sprintf("unexpected %s %s", r.Method, r.URL.Path), w)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestPromote(t *testing.T) {
+	server := makePromoteServer(t, []share.DeployEntry{})
+	client, err := api.NewClient(server.URL, TestToken)
+	assert.NoError(t, err)
+
+	result, err := share.Promote(t.Context(), &share.PromoteCommand{
+		API:  client,
+		Org:  TestOrg,
+		Game: TestGame,
+		From: "qa",
+		To:   "latest",
+	})
+	assert.NoError(t, err)
+
+	expected := []share.DeployEntry{
+		{Path: IndexV2.Path, Blake3: IndexV2.Blake3, ContentLength: int(IndexV2.ContentLength)},
+		{Path: GameV1.Path, Blake3: GameV1.Blake3, ContentLength: int(GameV1.ContentLength)},
+	}
+	assert.Equal(t, expected, server.started)
+	assert.Equal(t, expected, result.Manifest)
+	assert.Equal(t, int64(43), result.From.ID)
+	assert.Equal(t, int64(44), result.DeployID)
+	assert.Equal(t, TestDeployURL, result.URL)
+	assert.False(t, server.cancelled)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestPromoteMissingFiles(t *testing.T) {
+	server := makePromoteServer(t, []share.DeployEntry{{Path: GameV1.Path, Blake3: GameV1.Blake3, ContentLength: int(GameV1.ContentLength)}})
+	client, err := api.NewClient(server.URL, TestToken)
+	assert.NoError(t, err)
+
+	_, err = share.Promote(t.Context(), &share.PromoteCommand{
+		API:  client,
+		Org:  TestOrg,
+		Game: TestGame,
+		From: "qa",
+		To:   "latest",
+	})
+	assert.Error(t, "the server no longer has 1 files of deploy 43, deploy them again instead of promoting", err)
+	assert.True(t, server.cancelled)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestPromoteToItself(t *testing.T) {
+	_, err := share.Promote(t.Context(), &share.PromoteCommand{
+		API:  makeAPI(t),
+		Org:  TestOrg,
+		Game: TestGame,
+		From: "qa",
+		To:   "qa",
+	})
+	assert.Error(t, "cannot promote qa to itself", err)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestPromoteUnknownLabel(t *testing.T) {
+	server := makePromoteServer(t, []share.DeployEntry{})
+	client, err := api.NewClient(server.URL, TestToken)
+	assert.NoError(t, err)
+
+	_, err = share.Promote(t.Context(), &share.PromoteCommand{
+		API:  client,
+		Org:  TestOrg,
+		Game: TestGame,
+		From: "staging",
+		To:   "latest",
+	})
+	assert.Error(t, "label staging not found", err)
+	assert.Nil(t, server.started)
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,43 @@
+package share
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/vaguevoid/cloud-cli/internal/api"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+// ResolveDeploy gets a deploy by its ID, or by a label in which case it is
+// the deploy the server says is currently served under that label.
+func ResolveDeploy(ctx context.Context, client *api.Client, org string, game string, ref string) (*api.DeployDetail, error) {
+	if ref == "" {
+		return nil, fmt.Errorf("missing deploy ID or label")
+	}
+
+	id, err := strconv.ParseInt(ref, 10, 64)
+	if err != nil {
+		labels, err := client.ListLabels(ctx, org, game)
+		if err != nil {
+			return nil, err
+		}
+		index := slices.IndexFunc(labels, func(label api.Label) bool { return label.Name == ref })
+		if index < 0 {
+			return nil, fmt.Errorf("label %s not found", ref)
+		}
+		id = labels[index].DeployID
+	}
+
+	deploy, err := client.GetDeploy(ctx, org, game, id)
+	if api.IsNotFound(err) {
+		return nil, fmt.Errorf("deploy %d not found: %w", id, err)
+	} else if err != nil {
+		return nil, err
+	}
+	return deploy, nil
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,43 @@
+package share_test
+
+import (
+	"testing"
+
+	"github.com/vaguevoid/cloud-cli/internal/api"
+	"github.com/vaguevoid/cloud-cli/internal/domain/share"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+func TestResolveDeploy(t *testing.T) {
+	server := makeHistoryServer(t, []api.Deploy{
+		makeDeploy(43, "latest", false, 3),
+		makeDeploy(42, "latest", true, 2),
+		makeDeploy(41, "staging", true, 1),
+		makeDeploy(40, "beta", false, 0),
+	}, nil)
+	client := makeHistoryAPI(t, server)
+
+	deploy, err := share.ResolveDeploy(t.Context(), client, TestOrg, TestGame, "41")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(41), deploy.ID)
+
+	deploy, err = share.ResolveDeploy(t.Context(), client, TestOrg, TestGame, "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), deploy.ID) // the one served under the label, not the most recent
+
+	deploy, err = share.ResolveDeploy(t.Context(), client, TestOrg, TestGame, "staging")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(41), deploy.ID)
+
+	_, err = share.ResolveDeploy(t.Context(), client, TestOrg, TestGame, "99")
+	assert.Error(t, "deploy 99 not found: unexpected status code 404", err)
+	assert.True(t, api.IsNotFound(err))
+	assert.Equal(t, "deploy 99", api.ErrorResource(err))
+
+	_, err = share.ResolveDeploy(t.Context(), client, TestOrg, TestGame, "beta")
+	assert.Error(t, "label beta not found", err) // never guessed from the deploys
+}
+
+//-------------------------------------------------------------------------------------------------
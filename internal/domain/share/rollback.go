@@ -0,0 +1,190 @@
+package share
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/vaguevoid/cloud-cli/internal/api"
+)
+
+//=================================================================================================
+// ROLLBACK COMMAND
+//=================================================================================================
+
+type RollbackCommand struct {
+	API     *api.Client
+	Org     string
+	Game    string
+	Label   string
+	To      int64                          // the deploy to roll back to, zero for the one before the current deploy
+	Confirm func(plan *RollbackPlan) error // called before activating, returning an error aborts the rollback
+}
+
+type RollbackPlan struct {
+	Label string            `json:"label"`
+	From  *api.DeployDetail `json:"from,omitempty"` // nil when nothing is currently served under the label
+	To    *api.DeployDetail `json:"to"`
+	Diff  ManifestDiff      `json:"diff"`
+}
+
+type RollbackResult struct {
+	Plan       *RollbackPlan   `json:"plan"`
+	Activation *api.Activation `json:"activation"`
+}
+
+func Rollback(ctx context.Context, cmd *RollbackCommand) (*RollbackResult, error) {
+	if cmd.API == nil {
+		return nil, fmt.Errorf("missing api client")
+	} else if cmd.Org == "" {
+		return nil, fmt.Errorf("missing organization")
+	} else if cmd.Game == "" {
+		return nil, fmt.Errorf("missing game")
+	} else if cmd.Label == "" {
+		return nil, fmt.Errorf("missing label")
+	}
+	return cmd.execute(ctx)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+// ManifestDiff compares the manifest of the deploy being replaced (from) with
+// the one replacing it (to), entries are sorted by path.
+type ManifestDiff struct {
+	Added     []api.ManifestEntry `json:"added"`
+	Removed   []api.ManifestEntry `json:"removed"`
+	Changed   []api.ManifestEntry `json:"changed"` // as they are in to
+	Unchanged int                 `json:"unchanged"`
+}
+
+func (d ManifestDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+func DiffManifests(from []api.ManifestEntry, to []api.ManifestEntry) ManifestDiff {
+	diff := ManifestDiff{
+		Added:   []api.ManifestEntry{},
+		Removed: []api.ManifestEntry{},
+		Changed: []api.ManifestEntry{},
+	}
+	before := make(map[string]api.ManifestEntry, len(from))
+	for _, entry := range from {
+		before[entry.Path] = entry
+	}
+	for _, entry := range to {
+		old, ok := before[entry.Path]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, entry)
+		case old.Blake3 != entry.Blake3 || old.ContentLength != entry.ContentLength:
+			diff.Changed = append(diff.Changed, entry)
+		default:
+			diff.Unchanged++
+		}
+		delete(before, entry.Path)
+	}
+	for _, entry := range before {
+		diff.Removed = append(diff.Removed, entry)
+	}
+	byPath := func(a, b api.ManifestEntry) int { return cmp.Compare(a.Path, b.Path) }
+	slices.SortFunc(diff.Added, byPath)
+	slices.SortFunc(diff.Removed, byPath)
+	slices.SortFunc(diff.Changed, byPath)
+	return diff
+}
+
+//=================================================================================================
+// PRIVATE IMPLEMENTATION
+//=================================================================================================
+
+func (cmd *RollbackCommand) execute(ctx context.Context) (*RollbackResult, error) {
+
+	plan, err := cmd.plan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.Confirm != nil {
+		if err := cmd.Confirm(plan); err != nil {
+			return nil, err
+		}
+	}
+
+	activation, err := cmd.API.ActivateDeploy(ctx, cmd.Org, cmd.Game, plan.To.ID, cmd.Label)
+	if err != nil {
+		return nil, fmt.Errorf("failed to activate deploy %d: %w", plan.To.ID, err)
+	}
+
+	return &RollbackResult{
+		Plan:       plan,
+		Activation: activation,
+	}, nil
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func (cmd *RollbackCommand) plan(ctx context.Context) (*RollbackPlan, error) {
+	labels, err := cmd.API.ListLabels(ctx, cmd.Org, cmd.Game)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &RollbackPlan{Label: cmd.Label}
+	if index := slices.IndexFunc(labels, func(label api.Label) bool { return label.Name == cmd.Label }); index >= 0 {
+		currentID := labels[index].DeployID
+		if currentID == cmd.To {
+			return nil, fmt.Errorf("deploy %d is already live as %s", currentID, cmd.Label)
+		}
+		plan.From, err = cmd.API.GetDeploy(ctx, cmd.Org, cmd.Game, currentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deploy %d: %w", currentID, err)
+		}
+	}
+
+	toID := cmd.To
+	if toID == 0 {
+		if plan.From == nil {
+			return nil, fmt.Errorf("label %s not found", cmd.Label)
+		}
+		toID, err = cmd.previousDeploy(ctx, &plan.From.Deploy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	plan.To, err = cmd.API.GetDeploy(ctx, cmd.Org, cmd.Game, toID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deploy %d: %w", toID, err)
+	}
+	if plan.From != nil {
+		plan.Diff = DiffManifests(plan.From.Manifest, plan.To.Manifest)
+	} else {
+		plan.Diff = DiffManifests(nil, plan.To.Manifest)
+	}
+	return plan, nil
+}
+
+// previousDeploy is the most recent deploy with the label that was created
+// before current, the deploy the labels API says is served under it.
+func (cmd *RollbackCommand) previousDeploy(ctx context.Context, current *api.Deploy) (int64, error) {
+	deploys, err := cmd.API.ListDeploys(ctx, cmd.Org, cmd.Game)
+	if err != nil {
+		return 0, err
+	}
+	var previous *api.Deploy
+	for i, deploy := range deploys {
+		if deploy.Label != cmd.Label || deploy.ID == current.ID || !deploy.CreatedAt.Before(current.CreatedAt) {
+			continue
+		}
+		if previous == nil || deploy.CreatedAt.After(previous.CreatedAt) {
+			previous = &deploys[i]
+		}
+	}
+	if previous == nil {
+		return 0, fmt.Errorf("there is no deploy before %d labelled %s to roll back to", current.ID, cmd.Label)
+	}
+	return previous.ID, nil
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,251 @@
+package share_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vaguevoid/cloud-cli/internal/api"
+	"github.com/vaguevoid/cloud-cli/internal/domain/share"
+	"github.com/vaguevoid/cloud-cli/internal/lib/httpx"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+var (
+	IndexV1 = api.ManifestEntry{Path: "index.html", Blake3: "index-1", ContentLength: 10}
+	IndexV2 = api.ManifestEntry{Path: "index.html", Blake3: "index-2", ContentLength: 12}
+	GameV1  = api.ManifestEntry{Path: "game.wasm", Blake3: "game-1", ContentLength: 100}
+	Extra   = api.ManifestEntry{Path: "extra.png", Blake3: "extra", ContentLength: 5}
+)
+
+func makeDeploy(id int64, label string, active bool, day int) api.Deploy {
+	return api.Deploy{
+		ID:        id,
+		Label:     label,
+		Active:    active,
+		Slug:      fmt.Sprintf("slug-%d", id),
+		CreatedAt: time.Date(2025, 1, day, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+type historyServer struct {
+	*httptest.Server
+	labels    []api.Label // served by the labels API, by default the active deploys
+	activated int64
+	label     string
+}
+
+func makeHistoryServer(t *testing.T, deploys []api.Deploy, manifests map[int64][]api.ManifestEntry) *historyServer {
+	s := &historyServer{labels: []api.Label{}}
+	for _, deploy := range deploys {
+		if deploy.Active {
+			s.labels = append(s.labels, api.Label{Name: deploy.Label, DeployID: deploy.ID})
+		}
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/api/void/snakes/deploy" {
+			httpx.RespondOk(deploys, w)
+			return
+		}
+		if r.Method == http.MethodGet && r.URL.Path == "/api/void/snakes/labels" {
+			httpx.RespondOk(s.labels, w)
+			return
+		}
+		for _, deploy := range deploys {
+			switch fmt.Sprintf("%s %s", r.Method, r.URL.Path) {
+			case fmt.Sprintf("GET /api/void/snakes/deploy/%d", deploy.ID):
+				httpx.RespondOk(&api.DeployDetail{Deploy: deploy, Manifest: manifests[deploy.ID]}, w)
+				return
+			case fmt.Sprintf("POST /api/void/snakes/deploy/%d/activate", deploy.ID):
+				s.activated = deploy.ID
+				s.label = r.Header.Get(httpx.HeaderXDeployLabel)
+				httpx.RespondOk(&api.Activation{DeployID: deploy.ID, Slug: deploy.Slug, URL: TestDeployURL}, w)
+				return
+			}
+		}
+		if r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/void/snakes/deploy/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		httpx.RespondBadRequest(fmt.Sprintf("unexpected %s %s", r.Method, r.URL.Path), w)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func makeHistoryAPI(t *testing.T, server *historyServer) *api.Client {
+	client, err := api.NewClient(server.URL, TestToken)
+	assert.NoError(t, err)
+	return client
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestDiffManifests(t *testing.T) {
+	diff := share.DiffManifests(
+		[]api.ManifestEntry{IndexV1, GameV1, Extra},
+		[]api.ManifestEntry{IndexV2, GameV1, {Path: "new.js", Blake3: "new", ContentLength: 1}},
+	)
+	assert.Equal(t, []api.ManifestEntry{{Path: "new.js", Blake3: "new", ContentLength: 1}}, diff.Added)
+	assert.Equal(t, []api.ManifestEntry{Extra}, diff.Removed)
+	assert.Equal(t, []api.ManifestEntry{IndexV2}, diff.Changed)
+	assert.Equal(t, 1, diff.Unchanged)
+	assert.False(t, diff.Empty())
+	assert.True(t, share.DiffManifests([]api.ManifestEntry{GameV1}, []api.ManifestEntry{GameV1}).Empty())
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestRollbackToPreviousDeploy(t *testing.T) {
+	server := makeHistoryServer(t, []api.Deploy{
+		makeDeploy(43, "latest", true, 3),
+		makeDeploy(42, "staging", false, 2),
+		makeDeploy(41, "latest", false, 1),
+	}, map[int64][]api.ManifestEntry{
+		43: {IndexV2, GameV1},
+		41: {IndexV1, GameV1, Extra},
+	})
+
+	var confirmed *share.RollbackPlan
+	result, err := share.Rollback(t.Context(), &share.RollbackCommand{
+		API:   makeHistoryAPI(t, server),
+		Org:   TestOrg,
+		Game:  TestGame,
+		Label: "latest",
+		Confirm: func(plan *share.RollbackPlan) error {
+			confirmed = plan
+			return nil
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, confirmed, result.Plan)
+	assert.Equal(t, int64(43), result.Plan.From.ID)
+	assert.Equal(t, int64(41), result.Plan.To.ID)
+	assert.Equal(t, []api.ManifestEntry{Extra}, result.Plan.Diff.Added)
+	assert.Equal(t, []api.ManifestEntry{IndexV1}, result.Plan.Diff.Changed)
+	assert.Equal(t, 1, result.Plan.Diff.Unchanged)
+	assert.Equal(t, int64(41), server.activated)
+	assert.Equal(t, "latest", server.label)
+	assert.Equal(t, int64(41), result.Activation.DeployID)
+}
+
+func TestRollbackAfterRollback(t *testing.T) {
+	server := makeHistoryServer(t, []api.Deploy{
+		makeDeploy(43, "latest", false, 3),
+		makeDeploy(42, "latest", true, 2), // already rolled back once
+		makeDeploy(41, "latest", false, 1),
+	}, nil)
+
+	result, err := share.Rollback(t.Context(), &share.RollbackCommand{
+		API:   makeHistoryAPI(t, server),
+		Org:   TestOrg,
+		Game:  TestGame,
+		Label: "latest",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), result.Plan.From.ID)
+	assert.Equal(t, int64(41), server.activated)
+}
+
+func TestRollbackUsesLabels(t *testing.T) {
+	server := makeHistoryServer(t, []api.Deploy{
+		makeDeploy(43, "latest", false, 3),
+		makeDeploy(42, "qa", false, 2),
+		makeDeploy(41, "latest", false, 1),
+		makeDeploy(40, "latest", false, 0),
+	}, nil)
+	server.labels = []api.Label{{Name: "latest", DeployID: 41}} // e.g. after labels set, never guessed from the deploys
+
+	result, err := share.Rollback(t.Context(), &share.RollbackCommand{
+		API:   makeHistoryAPI(t, server),
+		Org:   TestOrg,
+		Game:  TestGame,
+		Label: "latest",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(41), result.Plan.From.ID)
+	assert.Equal(t, int64(40), server.activated)
+
+	server.labels = []api.Label{{Name: "latest", DeployID: 42}} // promoted from qa
+	result, err = share.Rollback(t.Context(), &share.RollbackCommand{
+		API:   makeHistoryAPI(t, server),
+		Org:   TestOrg,
+		Game:  TestGame,
+		Label: "latest",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), result.Plan.From.ID)
+	assert.Equal(t, int64(41), server.activated)
+}
+
+func TestRollbackToDeploy(t *testing.T) {
+	server := makeHistoryServer(t, []api.Deploy{
+		makeDeploy(43, "latest", true, 3),
+		makeDeploy(42, "staging", false, 2),
+	}, nil)
+
+	result, err := share.Rollback(t.Context(), &share.RollbackCommand{
+		API:   makeHistoryAPI(t, server),
+		Org:   TestOrg,
+		Game:  TestGame,
+		Label: "latest",
+		To:    42,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(43), result.Plan.From.ID)
+	assert.Equal(t, int64(42), server.activated)
+	assert.Equal(t, "latest", server.label)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestRollbackNothingToRollBackTo(t *testing.T) {
+	server := makeHistoryServer(t, []api.Deploy{
+		makeDeploy(43, "latest", true, 3),
+	}, nil)
+	cmd := &share.RollbackCommand{
+		API:   makeHistoryAPI(t, server),
+		Org:   TestOrg,
+		Game:  TestGame,
+		Label: "latest",
+	}
+
+	_, err := share.Rollback(t.Context(), cmd)
+	assert.Error(t, "there is no deploy before 43 labelled latest to roll back to", err)
+
+	cmd.Label = "beta"
+	_, err = share.Rollback(t.Context(), cmd)
+	assert.Error(t, "label beta not found", err)
+
+	cmd.Label = "latest"
+	cmd.To = 43
+	_, err = share.Rollback(t.Context(), cmd)
+	assert.Error(t, "deploy 43 is already live as latest", err)
+	assert.Equal(t, int64(0), server.activated)
+}
+
+func TestRollbackCancelled(t *testing.T) {
+	server := makeHistoryServer(t, []api.Deploy{
+		makeDeploy(43, "latest", true, 3),
+		makeDeploy(41, "latest", false, 1),
+	}, nil)
+	cancelled := errors.New("cancelled")
+
+	_, err := share.Rollback(t.Context(), &share.RollbackCommand{
+		API:     makeHistoryAPI(t, server),
+		Org:     TestOrg,
+		Game:    TestGame,
+		Label:   "latest",
+		Confirm: func(plan *share.RollbackPlan) error { return cancelled },
+	})
+	assert.Equal(t, cancelled, err)
+	assert.Equal(t, int64(0), server.activated)
+}
+
+//-------------------------------------------------------------------------------------------------
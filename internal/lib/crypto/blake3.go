@@ -22,16 +22,26 @@ func Blake3(value any) string {
 	}
 }
 
+// Blake3Reader is Blake3 for readers that can fail part way through (e.g. a
+// corrupt archive), returning the error instead of panicking.
+func Blake3Reader(r io.Reader) (string, error) {
+	hasher := blake3.New()
+	if _, err := io.Copy(hasher, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
 func blake3FromString(value string) string {
 	return blake3FromReader(strings.NewReader(value))
 }
 
 func blake3FromReader(r io.Reader) string {
-	hasher := blake3.New()
-	if _, err := io.Copy(hasher, r); err != nil {
+	hash, err := Blake3Reader(r)
+	if err != nil {
 		panic(err)
 	}
-	return hex.EncodeToString(hasher.Sum(nil))
+	return hash
 }
 
 //-------------------------------------------------------------------------------------------------
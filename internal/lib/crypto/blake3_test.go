@@ -1,9 +1,12 @@
 package crypto_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/vaguevoid/cloud-cli/internal/lib/crypto"
 	"github.com/vaguevoid/cloud-cli/internal/test/assert"
@@ -27,3 +30,14 @@ func TestBlake3(t *testing.T) {
 }
 
 //-------------------------------------------------------------------------------------------------
+
+func TestBlake3Reader(t *testing.T) {
+	hash, err := crypto.Blake3Reader(strings.NewReader("Hello World"))
+	assert.Nil(t, err)
+	assert.Equal(t, "41f8394111eb713a22165c46c90ab8f0fd9399c92028fd6d288944b23ff5bf76", hash)
+
+	_, err = crypto.Blake3Reader(iotest.ErrReader(errors.New("corrupt")))
+	assert.Error(t, "corrupt", err)
+}
+
+//-------------------------------------------------------------------------------------------------
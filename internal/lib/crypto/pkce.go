@@ -0,0 +1,35 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+const PKCEMethod = "S256"
+
+// RandomToken returns n random bytes encoded as unpadded base64url, suitable
+// for a state nonce or a PKCE code verifier.
+func RandomToken(n int) string {
+	bytes := make([]byte, n)
+	if _, err := rand.Read(bytes); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+// PKCEChallenge derives the S256 code challenge for a PKCE code verifier.
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// SecureCompare compares two secrets in constant time.
+func SecureCompare(a string, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,35 @@
+package crypto_test
+
+import (
+	"testing"
+
+	"github.com/vaguevoid/cloud-cli/internal/lib/crypto"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+func TestRandomToken(t *testing.T) {
+	a := crypto.RandomToken(32)
+	b := crypto.RandomToken(32)
+	assert.Equal(t, 43, len(a))
+	assert.Regexp(t, `^[A-Za-z0-9_-]+$`, a)
+	assert.True(t, a != b)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestPKCEChallenge(t *testing.T) {
+	// example from RFC 7636 appendix B
+	assert.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", crypto.PKCEChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestSecureCompare(t *testing.T) {
+	assert.True(t, crypto.SecureCompare("state", "state"))
+	assert.False(t, crypto.SecureCompare("state", "other"))
+	assert.False(t, crypto.SecureCompare("state", ""))
+}
+
+//-------------------------------------------------------------------------------------------------
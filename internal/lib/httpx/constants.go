@@ -1,9 +1,13 @@
 package httpx
 
 const (
-	ParamCLI    = "cli"
-	ParamJWT    = "jwt"
-	ParamOrigin = "origin"
+	ParamCLI                 = "cli"
+	ParamCode                = "code"
+	ParamCodeChallenge       = "code_challenge"
+	ParamCodeChallengeMethod = "code_challenge_method"
+	ParamJWT                 = "jwt"
+	ParamOrigin              = "origin"
+	ParamState               = "state"
 )
 
 const (
@@ -33,9 +37,11 @@ const (
 	HeaderHxRefresh                 = "HX-Refresh"
 	HeaderHxRequest                 = "HX-Request"
 	HeaderHxRetarget                = "HX-Retarget"
+	HeaderIdempotencyKey            = "Idempotency-Key"
 	HeaderIfModifiedSince           = "If-Modified-Since"
 	HeaderLastModified              = "Last-Modified"
 	HeaderLocation                  = "Location"
+	HeaderRetryAfter                = "Retry-After"
 	HeaderUserAgent                 = "User-Agent"
 	HeaderXDeployID                 = "X-Deploy-ID"
 	HeaderXDeployLabel              = "X-Deploy-Label"
@@ -46,6 +52,7 @@ const (
 	HeaderXForwardedPrefix          = "X-Forwarded-Prefix"
 	HeaderXForwardedProto           = "X-Forwarded-Proto"
 	HeaderXFrameOptions             = "X-Frame-Options"
+	HeaderXRequestID                = "X-Request-ID"
 )
 
 const (
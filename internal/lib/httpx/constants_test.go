@@ -11,8 +11,12 @@ import (
 
 func TestParams(t *testing.T) {
 	assert.Equal(t, "cli", httpx.ParamCLI)
+	assert.Equal(t, "code", httpx.ParamCode)
+	assert.Equal(t, "code_challenge", httpx.ParamCodeChallenge)
+	assert.Equal(t, "code_challenge_method", httpx.ParamCodeChallengeMethod)
 	assert.Equal(t, "jwt", httpx.ParamJWT)
 	assert.Equal(t, "origin", httpx.ParamOrigin)
+	assert.Equal(t, "state", httpx.ParamState)
 }
 
 //-------------------------------------------------------------------------------------------------
@@ -44,9 +48,11 @@ func TestHttpHeaders(t *testing.T) {
 	assert.Equal(t, "HX-Refresh", httpx.HeaderHxRefresh)
 	assert.Equal(t, "HX-Request", httpx.HeaderHxRequest)
 	assert.Equal(t, "HX-Retarget", httpx.HeaderHxRetarget)
+	assert.Equal(t, "Idempotency-Key", httpx.HeaderIdempotencyKey)
 	assert.Equal(t, "If-Modified-Since", httpx.HeaderIfModifiedSince)
 	assert.Equal(t, "Last-Modified", httpx.HeaderLastModified)
 	assert.Equal(t, "Location", httpx.HeaderLocation)
+	assert.Equal(t, "Retry-After", httpx.HeaderRetryAfter)
 	assert.Equal(t, "User-Agent", httpx.HeaderUserAgent)
 	assert.Equal(t, "X-Deploy-ID", httpx.HeaderXDeployID)
 	assert.Equal(t, "X-Deploy-Label", httpx.HeaderXDeployLabel)
@@ -57,6 +63,7 @@ func TestHttpHeaders(t *testing.T) {
 	assert.Equal(t, "X-Forwarded-Prefix", httpx.HeaderXForwardedPrefix)
 	assert.Equal(t, "X-Forwarded-Proto", httpx.HeaderXForwardedProto)
 	assert.Equal(t, "X-Frame-Options", httpx.HeaderXFrameOptions)
+	assert.Equal(t, "X-Request-ID", httpx.HeaderXRequestID)
 }
 
 //-------------------------------------------------------------------------------------------------
@@ -0,0 +1,173 @@
+package ignore
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+// Matcher implements .gitignore pattern semantics: blank lines and # comments
+// are skipped, a leading ! negates, a trailing / only matches directories, a
+// pattern containing a / is anchored to the root, and ** spans directories.
+// The last matching pattern wins.
+type Matcher struct {
+	rules []rule
+}
+
+type rule struct {
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+func New(patterns ...string) *Matcher {
+	m := &Matcher{}
+	for _, pattern := range patterns {
+		m.Add(pattern)
+	}
+	return m
+}
+
+func Load(path string) (*Matcher, error) {
+	m := New()
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	err = m.Read(f)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *Matcher) Read(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		m.Add(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+func (m *Matcher) Add(pattern string) {
+	pattern = strings.TrimSuffix(pattern, "\r")
+	pattern = trimTrailingSpaces(pattern)
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return
+	}
+
+	var r rule
+	if strings.HasPrefix(pattern, "!") {
+		r.negate = true
+		pattern = pattern[1:]
+	} else if strings.HasPrefix(pattern, `\!`) || strings.HasPrefix(pattern, `\#`) {
+		pattern = pattern[1:]
+	}
+
+	if strings.HasSuffix(pattern, "/") {
+		r.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	if pattern == "" {
+		return
+	}
+
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	expr := translate(pattern)
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+
+	rx, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return // invalid patterns are ignored, as git does
+	}
+	r.pattern = rx
+	m.rules = append(m.rules, r)
+}
+
+func (m *Matcher) Match(path string, isDir bool) bool {
+	path = strings.Trim(path, "/")
+	ignored := false
+	for _, r := range m.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if r.pattern.MatchString(path) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func trimTrailingSpaces(pattern string) string {
+	for strings.HasSuffix(pattern, " ") && !strings.HasSuffix(pattern, `\ `) {
+		pattern = pattern[:len(pattern)-1]
+	}
+	return pattern
+}
+
+func translate(pattern string) string {
+	var sb strings.Builder
+	n := len(pattern)
+	for i := 0; i < n; i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < n && pattern[i+1] == '*' {
+				atStart := i == 0 || pattern[i-1] == '/'
+				atEnd := i+2 == n || pattern[i+2] == '/'
+				if atStart && atEnd {
+					if i+2 == n {
+						sb.WriteString(".*") // trailing /** matches everything inside
+					} else {
+						sb.WriteString("(?:.*/)?") // leading **/ or middle /**/ matches zero or more directories
+						i++
+					}
+					i++
+					continue
+				}
+				for i+1 < n && pattern[i+1] == '*' {
+					i++
+				}
+			}
+			sb.WriteString("[^/]*")
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < n {
+				i++
+				sb.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,111 @@
+package ignore_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vaguevoid/cloud-cli/internal/lib/ignore"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+	"github.com/vaguevoid/cloud-cli/internal/test/mock"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+func TestMatchBasename(t *testing.T) {
+	m := ignore.New("*.map", ".DS_Store")
+	assert.True(t, m.Match("game.js.map", false))
+	assert.True(t, m.Match("assets/js/game.js.map", false))
+	assert.True(t, m.Match(".DS_Store", false))
+	assert.True(t, m.Match("assets/.DS_Store", false))
+	assert.False(t, m.Match("game.js", false))
+	assert.False(t, m.Match("map", false))
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestMatchAnchored(t *testing.T) {
+	m := ignore.New("/debug.log", "docs/*.md")
+	assert.True(t, m.Match("debug.log", false))
+	assert.False(t, m.Match("logs/debug.log", false))
+	assert.True(t, m.Match("docs/readme.md", false))
+	assert.False(t, m.Match("docs/nested/readme.md", false))
+	assert.False(t, m.Match("other/docs/readme.md", false))
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestMatchDirectoryOnly(t *testing.T) {
+	m := ignore.New("build/")
+	assert.True(t, m.Match("build", true))
+	assert.True(t, m.Match("src/build", true))
+	assert.False(t, m.Match("build", false))
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestMatchDoubleStar(t *testing.T) {
+	m := ignore.New("**/cache", "assets/**/raw", "tmp/**")
+	assert.True(t, m.Match("cache", true))
+	assert.True(t, m.Match("a/b/cache", true))
+	assert.True(t, m.Match("assets/raw", true))
+	assert.True(t, m.Match("assets/x/y/raw", true))
+	assert.True(t, m.Match("tmp/file.txt", false))
+	assert.True(t, m.Match("tmp/a/b/file.txt", false))
+	assert.False(t, m.Match("tmp", true))
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestMatchNegation(t *testing.T) {
+	m := ignore.New("*.log", "!important.log")
+	assert.True(t, m.Match("debug.log", false))
+	assert.False(t, m.Match("important.log", false))
+	assert.False(t, m.Match("logs/important.log", false))
+
+	m = ignore.New("!important.log", "*.log")
+	assert.True(t, m.Match("important.log", false), "last matching pattern wins")
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestMatchWildcards(t *testing.T) {
+	m := ignore.New("file?.txt", "*.[oa]", "*.[!c]pp", `\#notes`, `\!bang`)
+	assert.True(t, m.Match("file1.txt", false))
+	assert.False(t, m.Match("file10.txt", false))
+	assert.True(t, m.Match("lib.o", false))
+	assert.True(t, m.Match("lib.a", false))
+	assert.False(t, m.Match("lib.c", false))
+	assert.True(t, m.Match("main.hpp", false))
+	assert.False(t, m.Match("main.cpp", false))
+	assert.True(t, m.Match("#notes", false))
+	assert.True(t, m.Match("!bang", false))
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestRead(t *testing.T) {
+	m := ignore.New()
+	err := m.Read(strings.NewReader("# comment\n\n*.map   \r\n!keep.map\n"))
+	assert.Nil(t, err)
+	assert.True(t, m.Match("game.map", false))
+	assert.False(t, m.Match("keep.map", false))
+	assert.False(t, m.Match("# comment", false))
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestLoad(t *testing.T) {
+	tmp := mock.TempDir(t)
+	tmp.AddTextFile(t, ".voidignore", "*.map\n")
+
+	m, err := ignore.Load(filepath.Join(tmp.Dir, ".voidignore"))
+	assert.Nil(t, err)
+	assert.True(t, m.Match("game.map", false))
+
+	m, err = ignore.Load(filepath.Join(tmp.Dir, "missing"))
+	assert.Nil(t, err)
+	assert.False(t, m.Match("game.map", false))
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,148 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+type Format int
+
+const (
+	FormatText Format = iota
+	FormatJSON
+	FormatNDJSON
+)
+
+func ParseFormat(name string) (Format, error) {
+	switch name {
+	case "", "text":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	case "ndjson":
+		return FormatNDJSON, nil
+	default:
+		return FormatText, fmt.Errorf("unknown output format %q (expected text, json or ndjson)", name)
+	}
+}
+
+func (f Format) String() string {
+	switch f {
+	case FormatJSON:
+		return "json"
+	case FormatNDJSON:
+		return "ndjson"
+	default:
+		return "text"
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+
+// Printer separates human chatter from machine readable results. In text mode
+// everything goes to Out, otherwise chatter goes to Err so that Out only ever
+// contains JSON (a single document, or one event per line for ndjson).
+type Printer struct {
+	Format Format
+	Out    io.Writer
+	Err    io.Writer
+	Now    func() time.Time
+	mu     sync.Mutex
+}
+
+func New(format Format) *Printer {
+	return &Printer{
+		Format: format,
+		Out:    os.Stdout,
+		Err:    os.Stderr,
+		Now:    time.Now,
+	}
+}
+
+func (p *Printer) IsText() bool {
+	return p.Format == FormatText
+}
+
+// Chatter is where human readable progress goes.
+func (p *Printer) Chatter() io.Writer {
+	if p.IsText() {
+		return p.Out
+	}
+	return p.Err
+}
+
+// Printf writes human readable progress.
+func (p *Printer) Printf(format string, args ...any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintf(p.Chatter(), format, args...)
+}
+
+// Event writes a lifecycle event, only in ndjson mode. The fields of data are
+// merged into the event object alongside "event" and "time".
+func (p *Printer) Event(name string, data any) {
+	if p.Format != FormatNDJSON {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.writeLine(p.event(name, data))
+}
+
+// Result writes the final result of a command. Text mode calls text (if any),
+// json mode writes value as an indented document and ndjson mode writes it as
+// a final event called name.
+func (p *Printer) Result(name string, value any, text func(w io.Writer)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch p.Format {
+	case FormatText:
+		if text != nil {
+			text(p.Out)
+		}
+	case FormatJSON:
+		bytes, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			panic(err)
+		}
+		fmt.Fprintln(p.Out, string(bytes))
+	case FormatNDJSON:
+		p.writeLine(p.event(name, value))
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func (p *Printer) event(name string, data any) map[string]any {
+	event := make(map[string]any)
+	if data != nil {
+		bytes, err := json.Marshal(data)
+		if err != nil {
+			panic(err)
+		}
+		if json.Unmarshal(bytes, &event) != nil || event == nil {
+			event = map[string]any{"data": data} // not an object, nest it instead
+		}
+	}
+	event["event"] = name
+	if p.Now != nil {
+		event["time"] = p.Now().UTC().Format(time.RFC3339Nano)
+	}
+	return event
+}
+
+func (p *Printer) writeLine(value any) {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		panic(err)
+	}
+	p.Out.Write(append(bytes, '\n'))
+}
+
+//-------------------------------------------------------------------------------------------------
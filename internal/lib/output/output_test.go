@@ -0,0 +1,92 @@
+package output_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/vaguevoid/cloud-cli/internal/lib/output"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+type testResult struct {
+	ID  int    `json:"id"`
+	URL string `json:"url"`
+}
+
+func makePrinter(format output.Format) (*output.Printer, *bytes.Buffer, *bytes.Buffer) {
+	var out, err bytes.Buffer
+	p := output.New(format)
+	p.Out = &out
+	p.Err = &err
+	p.Now = func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) }
+	return p, &out, &err
+}
+
+func textResult(w io.Writer) {
+	fmt.Fprintln(w, "Deployed")
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestParseFormat(t *testing.T) {
+	for name, expected := range map[string]output.Format{
+		"":       output.FormatText,
+		"text":   output.FormatText,
+		"json":   output.FormatJSON,
+		"ndjson": output.FormatNDJSON,
+	} {
+		format, err := output.ParseFormat(name)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, format)
+	}
+
+	_, err := output.ParseFormat("yaml")
+	assert.Error(t, `unknown output format "yaml" (expected text, json or ndjson)`, err)
+
+	assert.Equal(t, "ndjson", output.FormatNDJSON.String())
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestTextOutput(t *testing.T) {
+	p, out, err := makePrinter(output.FormatText)
+	p.Printf("deploying %d files\n", 3)
+	p.Event("started", testResult{ID: 1})
+	p.Result("deployed", testResult{ID: 1}, textResult)
+	assert.Equal(t, "deploying 3 files\nDeployed\n", out.String())
+	assert.Equal(t, "", err.String())
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestJSONOutput(t *testing.T) {
+	p, out, err := makePrinter(output.FormatJSON)
+	p.Printf("deploying %d files\n", 3)
+	p.Event("started", testResult{ID: 1})
+	p.Result("deployed", testResult{ID: 1, URL: "https://play.void.dev/"}, textResult)
+	assert.Equal(t, "{\n  \"id\": 1,\n  \"url\": \"https://play.void.dev/\"\n}\n", out.String())
+	assert.Equal(t, "deploying 3 files\n", err.String())
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestNDJSONOutput(t *testing.T) {
+	p, out, err := makePrinter(output.FormatNDJSON)
+	p.Printf("deploying %d files\n", 3)
+	p.Event("started", testResult{ID: 1})
+	p.Event("list", []string{"a", "b"})
+	p.Result("deployed", testResult{ID: 1, URL: "https://play.void.dev/"}, textResult)
+	assert.Equal(t, ""+
+		`{"event":"started","id":1,"time":"2025-01-02T03:04:05Z","url":""}`+"\n"+
+		`{"data":["a","b"],"event":"list","time":"2025-01-02T03:04:05Z"}`+"\n"+
+		`{"event":"deployed","id":1,"time":"2025-01-02T03:04:05Z","url":"https://play.void.dev/"}`+"\n",
+		out.String())
+	assert.Equal(t, "deploying 3 files\n", err.String())
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,25 @@
+package output_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/vaguevoid/cloud-cli/internal/lib/output"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+func TestTable(t *testing.T) {
+	var out bytes.Buffer
+	output.Table(&out, []string{"Slug", "Name", "ID"}, [][]string{
+		{"void", "Void", "1"},
+		{"atari", "Atari Games", "22"},
+	})
+	assert.Equal(t, ""+
+		"SLUG   NAME         ID\n"+
+		"void   Void         1\n"+
+		"atari  Atari Games  22\n", out.String())
+}
+
+//-------------------------------------------------------------------------------------------------
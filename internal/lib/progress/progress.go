@@ -0,0 +1,176 @@
+package progress
+
+import (
+	"io"
+	"slices"
+	"sync"
+	"time"
+)
+
+//=================================================================================================
+// COUNTING READER
+//=================================================================================================
+
+// Reader wraps an io.Reader and reports the number of bytes read by each call
+// to Read (not the running total) to onRead.
+type Reader struct {
+	r      io.Reader
+	onRead func(n int64)
+	count  int64
+}
+
+func NewReader(r io.Reader, onRead func(n int64)) *Reader {
+	return &Reader{r: r, onRead: onRead}
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.count += int64(n)
+		if r.onRead != nil {
+			r.onRead(int64(n))
+		}
+	}
+	return n, err
+}
+
+func (r *Reader) Close() error {
+	if closer, ok := r.r.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (r *Reader) Count() int64 {
+	return r.count
+}
+
+//=================================================================================================
+// TRACKER
+//=================================================================================================
+
+// Tracker aggregates the progress of a set of concurrent file transfers, it
+// is safe to call from multiple goroutines.
+type Tracker struct {
+	Now func() time.Time
+
+	mu          sync.Mutex
+	files       int
+	bytes       int64
+	doneFiles   int
+	failedFiles int
+	sentBytes   int64
+	started     time.Time
+	active      []*FileProgress
+}
+
+type FileProgress struct {
+	Path string
+	Size int64
+	Sent int64
+}
+
+type Snapshot struct {
+	Files       int
+	Bytes       int64
+	DoneFiles   int
+	FailedFiles int
+	SentBytes   int64
+	Elapsed     time.Duration
+	Rate        float64       // bytes per second
+	ETA         time.Duration // zero when unknown
+	Active      []FileProgress
+}
+
+func NewTracker(files int, bytes int64) *Tracker {
+	return &Tracker{
+		Now:   time.Now,
+		files: files,
+		bytes: bytes,
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func (t *Tracker) Start(path string, size int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.started.IsZero() {
+		t.started = t.Now()
+	}
+	t.active = append(t.active, &FileProgress{Path: path, Size: size})
+}
+
+// Add records n more bytes sent for path, n is negative when a transfer is
+// rewound to be retried.
+func (t *Tracker) Add(path string, n int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sentBytes += n
+	if file := t.find(path); file != nil {
+		file.Sent += n
+	}
+}
+
+func (t *Tracker) Done(path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.doneFiles++
+	t.remove(path)
+}
+
+func (t *Tracker) Fail(path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.failedFiles++
+	if file := t.find(path); file != nil {
+		t.sentBytes -= file.Sent // never made it, don't count it as sent
+	}
+	t.remove(path)
+}
+
+func (t *Tracker) Snapshot() Snapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := Snapshot{
+		Files:       t.files,
+		Bytes:       t.bytes,
+		DoneFiles:   t.doneFiles,
+		FailedFiles: t.failedFiles,
+		SentBytes:   t.sentBytes,
+		Active:      make([]FileProgress, len(t.active)),
+	}
+	for i, file := range t.active {
+		s.Active[i] = *file
+	}
+	if !t.started.IsZero() {
+		s.Elapsed = t.Now().Sub(t.started)
+	}
+	if s.Elapsed > 0 && s.SentBytes > 0 {
+		s.Rate = float64(s.SentBytes) / s.Elapsed.Seconds()
+		if remaining := s.Bytes - s.SentBytes; remaining > 0 {
+			s.ETA = time.Duration(float64(remaining) / s.Rate * float64(time.Second))
+		}
+	}
+	return s
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func (t *Tracker) find(path string) *FileProgress {
+	for _, file := range t.active {
+		if file.Path == path {
+			return file
+		}
+	}
+	return nil
+}
+
+func (t *Tracker) remove(path string) {
+	t.active = slices.DeleteFunc(t.active, func(file *FileProgress) bool {
+		return file.Path == path
+	})
+}
+
+//-------------------------------------------------------------------------------------------------
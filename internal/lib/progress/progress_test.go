@@ -0,0 +1,90 @@
+package progress_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vaguevoid/cloud-cli/internal/lib/progress"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+func makeTracker(files int, bytes int64) (*progress.Tracker, *time.Time) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tracker := progress.NewTracker(files, bytes)
+	tracker.Now = func() time.Time { return now }
+	return tracker, &now
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestReader(t *testing.T) {
+	var reads []int64
+	r := progress.NewReader(strings.NewReader("hello world"), func(n int64) {
+		reads = append(reads, n)
+	})
+
+	buf := make([]byte, 4)
+	for {
+		if _, err := r.Read(buf); err == io.EOF {
+			break
+		}
+	}
+
+	assert.Equal(t, []int64{4, 4, 3}, reads)
+	assert.Equal(t, int64(11), r.Count())
+	assert.NoError(t, r.Close())
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestTracker(t *testing.T) {
+	tracker, now := makeTracker(3, 1000)
+
+	s := tracker.Snapshot()
+	assert.Equal(t, 3, s.Files)
+	assert.Equal(t, int64(1000), s.Bytes)
+	assert.Equal(t, time.Duration(0), s.Elapsed)
+	assert.Equal(t, 0.0, s.Rate)
+
+	tracker.Start("a.txt", 100)
+	tracker.Start("b.txt", 400)
+	tracker.Add("a.txt", 100)
+	tracker.Add("b.txt", 150)
+	*now = now.Add(time.Second)
+
+	s = tracker.Snapshot()
+	assert.Equal(t, int64(250), s.SentBytes)
+	assert.Equal(t, time.Second, s.Elapsed)
+	assert.Equal(t, 250.0, s.Rate)
+	assert.Equal(t, 3*time.Second, s.ETA)
+	assert.Equal(t, []progress.FileProgress{
+		{Path: "a.txt", Size: 100, Sent: 100},
+		{Path: "b.txt", Size: 400, Sent: 150},
+	}, s.Active)
+
+	tracker.Done("a.txt")
+	tracker.Add("b.txt", -150) // rewound for a retry
+	tracker.Fail("b.txt")
+
+	s = tracker.Snapshot()
+	assert.Equal(t, 1, s.DoneFiles)
+	assert.Equal(t, 1, s.FailedFiles)
+	assert.Equal(t, int64(100), s.SentBytes)
+	assert.Equal(t, []progress.FileProgress{}, s.Active)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestTrackerFailDiscardsPartialBytes(t *testing.T) {
+	tracker, _ := makeTracker(1, 100)
+	tracker.Start("a.txt", 100)
+	tracker.Add("a.txt", 60)
+	tracker.Fail("a.txt")
+	assert.Equal(t, int64(0), tracker.Snapshot().SentBytes)
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,160 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vaguevoid/cloud-cli/internal/lib/system"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+const (
+	TTYInterval   = 100 * time.Millisecond
+	PlainInterval = 5 * time.Second
+	barWidth      = 20
+	pathWidth     = 40
+)
+
+// Renderer periodically draws a Tracker. On a terminal it redraws an overall
+// bar plus one line per active transfer in place, otherwise it writes a plain
+// summary line every PlainInterval.
+type Renderer struct {
+	Out      io.Writer
+	TTY      bool
+	Interval time.Duration
+
+	tracker *Tracker
+	lines   int
+	stop    chan struct{}
+	done    sync.WaitGroup
+}
+
+func NewRenderer(out io.Writer, tracker *Tracker) *Renderer {
+	tty := system.IsTerminal(out)
+	interval := PlainInterval
+	if tty {
+		interval = TTYInterval
+	}
+	return &Renderer{
+		Out:      out,
+		TTY:      tty,
+		Interval: interval,
+		tracker:  tracker,
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func (r *Renderer) Start() {
+	r.stop = make(chan struct{})
+	r.done.Add(1)
+	go func() {
+		defer r.done.Done()
+		ticker := time.NewTicker(r.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				r.Render()
+			}
+		}
+	}()
+}
+
+// Stop ends the render loop and draws the final state.
+func (r *Renderer) Stop() {
+	if r.stop != nil {
+		close(r.stop)
+		r.done.Wait()
+		r.stop = nil
+	}
+	r.Render()
+}
+
+func (r *Renderer) Render() {
+	s := r.tracker.Snapshot()
+	if !r.TTY {
+		fmt.Fprintln(r.Out, Summary(s))
+		return
+	}
+
+	var sb strings.Builder
+	if r.lines > 0 {
+		fmt.Fprintf(&sb, "\x1b[%dA", r.lines) // back to the top of the previous frame
+	}
+	lines := 0
+	fmt.Fprintf(&sb, "\x1b[2K%s %s\n", bar(s.SentBytes, s.Bytes), Summary(s))
+	lines++
+	for _, file := range s.Active {
+		fmt.Fprintf(&sb, "\x1b[2K%s %s %s\n", bar(file.Sent, file.Size), truncate(file.Path, pathWidth), FormatBytes(file.Size))
+		lines++
+	}
+	if extra := r.lines - lines; extra > 0 {
+		sb.WriteString(strings.Repeat("\x1b[2K\n", extra)) // clear lines left over from a taller frame
+		fmt.Fprintf(&sb, "\x1b[%dA", extra)
+	}
+	r.lines = lines
+	io.WriteString(r.Out, sb.String())
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func Summary(s Snapshot) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d / %d files, %s / %s", s.DoneFiles, s.Files, FormatBytes(s.SentBytes), FormatBytes(s.Bytes))
+	if s.Bytes > 0 {
+		fmt.Fprintf(&sb, " (%d%%)", percent(s.SentBytes, s.Bytes))
+	}
+	if s.Rate > 0 {
+		fmt.Fprintf(&sb, ", %s/s", FormatBytes(int64(s.Rate)))
+	}
+	if s.ETA > 0 {
+		fmt.Fprintf(&sb, ", ETA %s", s.ETA.Round(time.Second))
+	}
+	if s.FailedFiles > 0 {
+		fmt.Fprintf(&sb, ", %d failed", s.FailedFiles)
+	}
+	return sb.String()
+}
+
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func percent(n, total int64) int64 {
+	if total <= 0 {
+		return 100
+	}
+	return min(max(n*100/total, 0), 100)
+}
+
+func bar(n, total int64) string {
+	filled := int(percent(n, total) * barWidth / 100)
+	return "[" + strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled) + "]"
+}
+
+func truncate(s string, width int) string {
+	if len(s) <= width {
+		return s + strings.Repeat(" ", width-len(s))
+	}
+	return "..." + s[len(s)-width+3:]
+}
+
+//-------------------------------------------------------------------------------------------------
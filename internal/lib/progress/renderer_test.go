@@ -0,0 +1,104 @@
+package progress_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/vaguevoid/cloud-cli/internal/lib/progress"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "0 B", progress.FormatBytes(0))
+	assert.Equal(t, "1023 B", progress.FormatBytes(1023))
+	assert.Equal(t, "1.0 KiB", progress.FormatBytes(1024))
+	assert.Equal(t, "1.5 MiB", progress.FormatBytes(1536*1024))
+	assert.Equal(t, "2.0 GiB", progress.FormatBytes(2*1024*1024*1024))
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestSummary(t *testing.T) {
+	assert.Equal(t, "0 / 2 files, 0 B / 2.0 KiB (0%)", progress.Summary(progress.Snapshot{
+		Files: 2,
+		Bytes: 2048,
+	}))
+	assert.Equal(t, "1 / 2 files, 1.0 KiB / 2.0 KiB (50%), 512 B/s, ETA 2s, 1 failed", progress.Summary(progress.Snapshot{
+		Files:       2,
+		Bytes:       2048,
+		DoneFiles:   1,
+		FailedFiles: 1,
+		SentBytes:   1024,
+		Rate:        512,
+		ETA:         2 * time.Second,
+	}))
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestRendererPlain(t *testing.T) {
+	var out bytes.Buffer
+	tracker, _ := makeTracker(2, 300)
+	renderer := progress.NewRenderer(&out, tracker)
+	assert.False(t, renderer.TTY)
+	assert.Equal(t, progress.PlainInterval, renderer.Interval)
+
+	tracker.Start("a.txt", 100)
+	tracker.Add("a.txt", 100)
+	tracker.Done("a.txt")
+	renderer.Render()
+
+	assert.Equal(t, "1 / 2 files, 100 B / 300 B (33%)\n", out.String())
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestRendererTTY(t *testing.T) {
+	var out bytes.Buffer
+	tracker, _ := makeTracker(2, 300)
+	renderer := progress.NewRenderer(&out, tracker)
+	renderer.TTY = true
+
+	tracker.Start("a.txt", 100)
+	tracker.Add("a.txt", 50)
+	renderer.Render()
+
+	assert.Equal(t, ""+
+		"\x1b[2K[===                 ] 0 / 2 files, 50 B / 300 B (16%)\n"+
+		"\x1b[2K[==========          ] a.txt                                    100 B\n",
+		out.String())
+
+	out.Reset()
+	tracker.Add("a.txt", 50)
+	tracker.Done("a.txt")
+	renderer.Render()
+
+	assert.Equal(t, ""+
+		"\x1b[2A"+
+		"\x1b[2K[======              ] 1 / 2 files, 100 B / 300 B (33%)\n"+
+		"\x1b[2K\n"+
+		"\x1b[1A",
+		out.String())
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestRendererStartStop(t *testing.T) {
+	var out bytes.Buffer
+	tracker, _ := makeTracker(1, 100)
+	renderer := progress.NewRenderer(&out, tracker)
+	renderer.Interval = time.Millisecond
+
+	renderer.Start()
+	tracker.Start("a.txt", 100)
+	tracker.Add("a.txt", 100)
+	tracker.Done("a.txt")
+	renderer.Stop()
+
+	assert.True(t, bytes.HasSuffix(out.Bytes(), []byte("1 / 1 files, 100 B / 100 B (100%)\n")))
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,127 @@
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/vaguevoid/cloud-cli/internal/lib/system"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+var ErrNoInput = errors.New("no input")
+
+// Prompter asks questions on Out and reads line based answers from In.
+type Prompter struct {
+	In  *bufio.Reader
+	Out io.Writer
+	raw io.Reader
+}
+
+func New(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{
+		In:  bufio.NewReader(in),
+		Out: out,
+		raw: in,
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+
+// Input asks for a line of text, an empty answer returns value.
+func (p *Prompter) Input(label string, value string) (string, error) {
+	if value != "" {
+		fmt.Fprintf(p.Out, "%s [%s]: ", label, value)
+	} else {
+		fmt.Fprintf(p.Out, "%s: ", label)
+	}
+	answer, err := p.readLine()
+	if err != nil {
+		return "", err
+	} else if answer == "" {
+		return value, nil
+	}
+	return answer, nil
+}
+
+// Select lists the options and asks for one by number, an empty answer
+// returns def. Invalid answers are asked again.
+func (p *Prompter) Select(label string, options []string, def int) (int, error) {
+	if len(options) == 0 {
+		return 0, fmt.Errorf("no options for %s", strings.ToLower(label))
+	}
+	fmt.Fprintln(p.Out, label)
+	for i, option := range options {
+		fmt.Fprintf(p.Out, "  %d) %s\n", i+1, option)
+	}
+	for {
+		fmt.Fprintf(p.Out, "Choose 1-%d [%d]: ", len(options), def+1)
+		answer, err := p.readLine()
+		if err != nil {
+			return 0, err
+		} else if answer == "" {
+			return def, nil
+		}
+		n, err := strconv.Atoi(answer)
+		if err == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		fmt.Fprintf(p.Out, "%q is not a valid choice\n", answer)
+	}
+}
+
+// Password asks for a secret without echoing it when reading from a terminal,
+// otherwise (e.g. in tests) it reads a plain line.
+func (p *Prompter) Password(label string) (string, error) {
+	fmt.Fprintf(p.Out, "%s: ", label)
+	if f, ok := p.raw.(*os.File); ok && system.IsTerminal(f) {
+		password, err := system.ReadPassword(f)
+		fmt.Fprintln(p.Out) // the user's enter wasn't echoed either
+		return password, err
+	}
+	return p.readLine()
+}
+
+// Confirm asks a yes/no question, an empty answer returns def.
+func (p *Prompter) Confirm(label string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		fmt.Fprintf(p.Out, "%s [%s]: ", label, hint)
+		answer, err := p.readLine()
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func (p *Prompter) readLine() (string, error) {
+	line, err := p.In.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	} else if err == io.EOF {
+		return "", ErrNoInput
+	} else if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+//-------------------------------------------------------------------------------------------------
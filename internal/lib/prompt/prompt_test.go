@@ -0,0 +1,91 @@
+package prompt_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/vaguevoid/cloud-cli/internal/lib/prompt"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+func makePrompter(input string) (*prompt.Prompter, *bytes.Buffer) {
+	var out bytes.Buffer
+	return prompt.New(strings.NewReader(input), &out), &out
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestInput(t *testing.T) {
+	p, out := makePrompter("snakes\n\n")
+
+	value, err := p.Input("Game name", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "snakes", value)
+
+	value, err = p.Input("Build directory", "dist")
+	assert.NoError(t, err)
+	assert.Equal(t, "dist", value)
+
+	assert.Equal(t, "Game name: Build directory [dist]: ", out.String())
+
+	_, err = p.Input("Game name", "")
+	assert.Equal(t, prompt.ErrNoInput, err)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestSelect(t *testing.T) {
+	p, out := makePrompter("7\nnope\n2\n\n")
+
+	index, err := p.Select("Organization", []string{"Void", "Atari"}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, index)
+	assert.Equal(t, ""+
+		"Organization\n"+
+		"  1) Void\n"+
+		"  2) Atari\n"+
+		"Choose 1-2 [1]: \"7\" is not a valid choice\n"+
+		"Choose 1-2 [1]: \"nope\" is not a valid choice\n"+
+		"Choose 1-2 [1]: ", out.String())
+
+	index, err = p.Select("Organization", []string{"Void", "Atari"}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, index)
+
+	_, err = p.Select("Game", nil, 0)
+	assert.Error(t, "no options for game", err)
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestPassword(t *testing.T) {
+	p, out := makePrompter("s3cret\n")
+
+	password, err := p.Password("Password")
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cret", password)
+	assert.Equal(t, "Password: ", out.String())
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestConfirm(t *testing.T) {
+	p, _ := makePrompter("maybe\ny\n\nNO")
+
+	ok, err := p.Confirm("Overwrite?", false)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = p.Confirm("Overwrite?", true)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = p.Confirm("Overwrite?", true)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+//-------------------------------------------------------------------------------------------------
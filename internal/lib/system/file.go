@@ -0,0 +1,36 @@
+package system
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+// WriteFileAtomic replaces path with what write writes, through a temp file
+// in the same directory that is renamed into place, so a reader never sees a
+// partial file and two writers never share a temp file.
+func WriteFileAtomic(path string, perm os.FileMode, write func(w io.Writer) error) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	err = f.Chmod(perm)
+	if err == nil {
+		err = write(f)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,54 @@
+package system_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/vaguevoid/cloud-cli/internal/lib/system"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+	"github.com/vaguevoid/cloud-cli/internal/test/mock"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+func TestWriteFileAtomic(t *testing.T) {
+	tmp := mock.TempDir(t)
+	tmp.AddTextFile(t, "config.toml", "old")
+	path := filepath.Join(tmp.Dir, "config.toml")
+
+	err := system.WriteFileAtomic(path, 0600, func(w io.Writer) error {
+		_, err := io.WriteString(w, "new")
+		return err
+	})
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", string(content))
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	failed := errors.New("failed")
+	err = system.WriteFileAtomic(path, 0600, func(w io.Writer) error {
+		io.WriteString(w, "partial")
+		return failed
+	})
+	assert.Equal(t, failed, err)
+
+	content, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", string(content))
+
+	entries, err := os.ReadDir(tmp.Dir)
+	assert.NoError(t, err)
+	assert.Length(t, 1, entries) // no temp file left behind
+}
+
+//-------------------------------------------------------------------------------------------------
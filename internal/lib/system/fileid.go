@@ -0,0 +1,19 @@
+//go:build unix
+
+package system
+
+import (
+	"os"
+	"syscall"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+func FileID(info os.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Ino)
+	}
+	return 0
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package system
+
+import (
+	"os"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+func FileID(info os.FileInfo) uint64 {
+	return 0 // not available, callers fall back to size and modification time
+}
+
+//-------------------------------------------------------------------------------------------------
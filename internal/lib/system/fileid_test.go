@@ -0,0 +1,34 @@
+package system_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/vaguevoid/cloud-cli/internal/lib/system"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+	"github.com/vaguevoid/cloud-cli/internal/test/mock"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+func TestFileID(t *testing.T) {
+	tmp := mock.TempDir(t)
+	tmp.AddTextFile(t, "first.txt", "first")
+	tmp.AddTextFile(t, "second.txt", "second")
+
+	first, err := os.Stat(filepath.Join(tmp.Dir, "first.txt"))
+	assert.Nil(t, err)
+	second, err := os.Stat(filepath.Join(tmp.Dir, "second.txt"))
+	assert.Nil(t, err)
+
+	if runtime.GOOS == "windows" {
+		assert.Equal(t, uint64(0), system.FileID(first))
+	} else {
+		assert.NotEmpty(t, system.FileID(first))
+		assert.True(t, system.FileID(first) != system.FileID(second))
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
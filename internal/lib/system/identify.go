@@ -51,3 +51,22 @@ func OpenCommand(OperatingSystem OperatingSystem, url string) (string, []string)
 }
 
 //-------------------------------------------------------------------------------------------------
+
+// HasDisplay reports whether a browser launched on this machine would be seen
+// by the user, it is false over SSH and, on linux, without an X11 or Wayland
+// display (e.g. in a container).
+func HasDisplay(os OperatingSystem, getenv func(key string) string) bool {
+	if getenv("SSH_CONNECTION") != "" || getenv("SSH_TTY") != "" {
+		return false
+	}
+	switch os {
+	case OperatingSystemMac, OperatingSystemWindows:
+		return true
+	case OperatingSystemLinux:
+		return getenv("DISPLAY") != "" || getenv("WAYLAND_DISPLAY") != ""
+	default:
+		return false
+	}
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -52,3 +52,27 @@ func TestOpenCommand(t *testing.T) {
 }
 
 //-------------------------------------------------------------------------------------------------
+
+func TestHasDisplay(t *testing.T) {
+	env := func(vars ...string) func(string) string {
+		return func(key string) string {
+			for i := 0; i+1 < len(vars); i += 2 {
+				if vars[i] == key {
+					return vars[i+1]
+				}
+			}
+			return ""
+		}
+	}
+
+	assert.True(t, system.HasDisplay(system.OperatingSystemMac, env()))
+	assert.True(t, system.HasDisplay(system.OperatingSystemWindows, env()))
+	assert.False(t, system.HasDisplay(system.OperatingSystemLinux, env()))
+	assert.True(t, system.HasDisplay(system.OperatingSystemLinux, env("DISPLAY", ":0")))
+	assert.True(t, system.HasDisplay(system.OperatingSystemLinux, env("WAYLAND_DISPLAY", "wayland-0")))
+	assert.False(t, system.HasDisplay(system.OperatingSystemLinux, env("DISPLAY", ":0", "SSH_CONNECTION", "10.0.0.1 22 10.0.0.2 22")))
+	assert.False(t, system.HasDisplay(system.OperatingSystemMac, env("SSH_TTY", "/dev/ttys001")))
+	assert.False(t, system.HasDisplay(system.OperatingSystemUnknown, env("DISPLAY", ":0")))
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -1,6 +1,7 @@
 package system
 
 import (
+	"os"
 	"os/exec"
 )
 
@@ -29,4 +30,8 @@ func (r *SystemRuntime) Open(url string) {
 	r.ExecuteCommand(cmd, args...).Start()
 }
 
+func (r *SystemRuntime) HasDisplay() bool {
+	return HasDisplay(r.OperatingSystem, os.Getenv)
+}
+
 //-------------------------------------------------------------------------------------------------
@@ -0,0 +1,28 @@
+package system
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+// IsTerminal reports whether v is an *os.File attached to a terminal, pipes,
+// regular files, in-memory buffers and the null device are not.
+func IsTerminal(v any) bool {
+	f, ok := v.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// ReadPassword reads a line from the terminal f without echoing it.
+func ReadPassword(f *os.File) (string, error) {
+	if !IsTerminal(f) {
+		return "", fmt.Errorf("cannot read a password without a terminal")
+	}
+	password, err := term.ReadPassword(int(f.Fd()))
+	return string(password), err
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,40 @@
+package system_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vaguevoid/cloud-cli/internal/lib/system"
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+)
+
+//-------------------------------------------------------------------------------------------------
+
+func TestIsTerminal(t *testing.T) {
+	assert.False(t, system.IsTerminal(&bytes.Buffer{}))
+	assert.False(t, system.IsTerminal(nil))
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "file.txt"))
+	assert.NoError(t, err)
+	defer f.Close()
+	assert.False(t, system.IsTerminal(f))
+
+	null, err := os.Open(os.DevNull)
+	assert.NoError(t, err)
+	defer null.Close()
+	assert.False(t, system.IsTerminal(null))
+}
+
+//-------------------------------------------------------------------------------------------------
+
+func TestReadPasswordWithoutTerminal(t *testing.T) {
+	null, err := os.Open(os.DevNull)
+	assert.NoError(t, err)
+	defer null.Close()
+	_, err = system.ReadPassword(null)
+	assert.Error(t, "cannot read a password without a terminal", err)
+}
+
+//-------------------------------------------------------------------------------------------------
@@ -0,0 +1,51 @@
+package mock
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Prompter answers prompts from a script, Select answers must match the text
+// of an option and an empty Input answer accepts the default.
+func Prompter(answers ...string) *MockPrompter {
+	return &MockPrompter{
+		Answers: answers,
+	}
+}
+
+type MockPrompter struct {
+	Answers []string
+	Asked   []string
+}
+
+func (p *MockPrompter) Select(label string, options []string, def int) (int, error) {
+	answer, err := p.next(label)
+	if err != nil {
+		return 0, err
+	}
+	index := slices.Index(options, answer)
+	if index < 0 {
+		return 0, fmt.Errorf("%q is not one of the %s options %q", answer, label, options)
+	}
+	return index, nil
+}
+
+func (p *MockPrompter) Input(label string, value string) (string, error) {
+	answer, err := p.next(label)
+	if err != nil {
+		return "", err
+	} else if answer == "" {
+		return value, nil
+	}
+	return answer, nil
+}
+
+func (p *MockPrompter) next(label string) (string, error) {
+	p.Asked = append(p.Asked, label)
+	if len(p.Answers) == 0 {
+		return "", fmt.Errorf("unexpected prompt %s", label)
+	}
+	answer := p.Answers[0]
+	p.Answers = p.Answers[1:]
+	return answer, nil
+}
@@ -0,0 +1,28 @@
+package mock_test
+
+import (
+	"testing"
+
+	"github.com/vaguevoid/cloud-cli/internal/test/assert"
+	"github.com/vaguevoid/cloud-cli/internal/test/mock"
+)
+
+func TestMockPrompter(t *testing.T) {
+	prompter := mock.Prompter("Atari", "", "snakes")
+
+	index, err := prompter.Select("Organization", []string{"Void", "Atari"}, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, index)
+
+	value, err := prompter.Input("Build directory", "dist")
+	assert.Nil(t, err)
+	assert.Equal(t, "dist", value)
+
+	_, err = prompter.Select("Game", []string{"tetris"}, 0)
+	assert.Error(t, `"snakes" is not one of the Game options ["tetris"]`, err)
+
+	_, err = prompter.Input("Label", "")
+	assert.Error(t, "unexpected prompt Label", err)
+
+	assert.Equal(t, []string{"Organization", "Build directory", "Game", "Label"}, prompter.Asked)
+}
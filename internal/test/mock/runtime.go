@@ -1,13 +1,26 @@
 package mock
 
+import "sync"
+
 func Runtime() *MockRuntime {
 	return &MockRuntime{}
 }
 
+// MockRuntime records the last URL opened, it is opened by the code under
+// test while the test reads it from another goroutine.
 type MockRuntime struct {
-	OpenedURL string
+	mu        sync.Mutex
+	openedURL string
 }
 
 func (b *MockRuntime) Open(url string) {
-	b.OpenedURL = url
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.openedURL = url
+}
+
+func (b *MockRuntime) OpenedURL() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.openedURL
 }
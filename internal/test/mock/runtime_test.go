@@ -15,9 +15,9 @@ const TestURL = "https://play.void.dev"
 
 func TestMockRuntime(t *testing.T) {
 	runtime := mock.Runtime()
-	assert.Empty(t, runtime.OpenedURL)
+	assert.Empty(t, runtime.OpenedURL())
 	runtime.Open(TestURL)
-	assert.Equal(t, TestURL, runtime.OpenedURL)
+	assert.Equal(t, TestURL, runtime.OpenedURL())
 }
 
 //-------------------------------------------------------------------------------------------------